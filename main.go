@@ -1,48 +1,355 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alowayed/coding-problems/orth"
+	"github.com/alowayed/coding-problems/orth/stats"
 )
 
-func run() error {
+// run runs the subcommand named by args[0], watching one orthotope fill up if
+// there is none.
+func run(args []string) error {
+
+	if len(args) == 0 {
+		return watch(nil)
+	}
+
+	switch args[0] {
+	case "watch":
+		return watch(args[1:])
+	case "replay":
+		return replay(args[1:])
+	case "sweep":
+		return sweep(args[1:])
+	case "scaling":
+		return scaling(args[1:])
+	case "clusters":
+		return clusters(args[1:])
+	default:
+		return fmt.Errorf("unknown subcommand %q, want watch, replay, sweep, scaling or clusters", args[0])
+	}
+}
+
+// watch draws bridges being built at random on one orthotope until it is
+// complete, or records them as an animated GIF.
+func watch(args []string) error {
+
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	events := fs.String("events", "", "file to write the event log to")
+	gifOut := fs.String("gif", "", "file to record the build to as an animated GIF instead")
+	every := fs.Int("every", 1, "builds between GIF frames")
+	delay := fs.Duration("delay", 300*time.Millisecond, "time each frame shows")
+	cell := fs.Int("cell", 8, "side of a cell in GIF pixels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
 
 	dimensions := []int{15, 10}
 
-	o, err := orth.New(dimensions)
+	o, err := orth.New(dimensions, orth.WithEventLog())
 	if err != nil {
 		return err
 	}
+	var a *orth.Animation
+	if *gifOut != "" {
+		a, err = orth.NewAnimation(orth.GIFOptions{
+			PNGOptions: orth.PNGOptions{CellSize: *cell},
+			Every:      *every,
+			Delay:      *delay,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	term := orth.NewTerminal(os.Stdout)
 	complete, err := o.BridgeComplete()
 	if err != nil {
 		return err
 	}
-	for !complete {
+	for step := 1; !complete; step++ {
 		_, err = o.BuildRandom()
 		if err != nil {
 			return fmt.Errorf("failed to build bridge: %w", err)
 		}
-		log.Printf("\n%+v", o)
-		log.Println("----------")
 		complete, err = o.BridgeComplete()
 		if err != nil {
 			return err
 		}
-		time.Sleep(time.Millisecond * 300)
+		if a != nil {
+			if err := a.Capture(o); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := term.Draw(o, step); err != nil {
+			return err
+		}
+		if term.Interactive() {
+			time.Sleep(*delay)
+		}
 	}
 
-	log.Println("--- BRIDGE COMPLETED")
+	path, err := o.SpanningPath()
+	if err != nil {
+		return err
+	}
+	log.Printf("--- BRIDGE COMPLETED along %v", path)
+
+	if a != nil {
+		err := write(*gifOut, func(w io.Writer) error {
+			return a.WriteGIF(w, o)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if *events == "" {
+		return nil
+	}
+	return write(*events, func(w io.Writer) error {
+		return orth.WriteEvents(w, o.Events())
+	})
+}
+
+// replay logs an orthotope as it was after some steps of an event log.
+func replay(args []string) error {
+
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	lengths := fs.String("lengths", "15,10", "comma separated side lengths")
+	events := fs.String("events", "", "file to read the event log from")
+	step := fs.Int("step", math.MaxInt32, "number of events to replay")
+	svg := fs.String("svg", "", "file to draw the orthotope to as SVG")
+	pngOut := fs.String("png", "", "file to draw the orthotope to as PNG")
+	cell := fs.Int("cell", 4, "side of a cell in PNG pixels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ls, err := parseLengths(*lengths)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*events)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	history, err := orth.ReadEvents(f)
+	if err != nil {
+		return err
+	}
+
+	o, err := orth.Replay(ls, history, *step)
+	if err != nil {
+		return err
+	}
+	complete, err := o.BridgeComplete()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v\ncomplete: %v\n", o, complete)
+
+	if *svg != "" {
+		err := write(*svg, func(w io.Writer) error {
+			return o.WriteSVG(w)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if *pngOut != "" {
+		return write(*pngOut, func(w io.Writer) error {
+			return o.WritePNG(w, orth.PNGOptions{CellSize: *cell})
+		})
+	}
 
 	return nil
 }
 
+// sweep writes the completion probability R(p) of an orthotope as a table.
+func sweep(args []string) error {
+
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	lengths := fs.String("lengths", "15,10", "comma separated side lengths")
+	trials := fs.Int("trials", 1000, "number of sweeps")
+	points := fs.Int("points", 100, "number of intervals between p = 0 and p = 1")
+	seed := fs.Int64("seed", 1, "seed every sweep's seed is derived from")
+	workers := fs.Int("workers", 0, "sweeps run at once, or 0 for one per CPU")
+	direction := fs.String("direction", "undirected", "paths that count: undirected, forward or sideways")
+	out := fs.String("o", "", "file to write the table to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := directionOptions(*direction)
+	if err != nil {
+		return err
+	}
+
+	ls, err := parseLengths(*lengths)
+	if err != nil {
+		return err
+	}
+
+	r, err := newRunner(*seed, *workers)
+	if err != nil {
+		return err
+	}
+
+	c, err := r.Sweep(context.Background(), ls, *trials, stats.Grid(*points), opts...)
+	if err != nil {
+		return err
+	}
+
+	return write(*out, c.WriteTSV)
+}
+
+// scaling writes a finite-size scaling analysis of hypercubes of several sizes.
+func scaling(args []string) error {
+
+	fs := flag.NewFlagSet("scaling", flag.ContinueOnError)
+	dims := fs.Int("dims", 2, "number of dimensions")
+	sizes := fs.String("sizes", "8,16,32,64", "comma separated side lengths L")
+	trials := fs.Int("trials", 1000, "number of trials at each size")
+	seed := fs.Int64("seed", 1, "seed every trial's seed is derived from")
+	workers := fs.Int("workers", 0, "trials run at once, or 0 for one per CPU")
+	direction := fs.String("direction", "undirected", "paths that count: undirected, forward or sideways")
+	out := fs.String("o", "", "file to write the table to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := directionOptions(*direction)
+	if err != nil {
+		return err
+	}
+
+	ls, err := parseLengths(*sizes)
+	if err != nil {
+		return err
+	}
+
+	r, err := newRunner(*seed, *workers)
+	if err != nil {
+		return err
+	}
+
+	s, err := r.Scale(context.Background(), *dims, ls, *trials, opts...)
+	if err != nil {
+		return err
+	}
+
+	return write(*out, s.WriteTSV)
+}
+
+// clusters writes the cluster size distribution at a fixed occupied fraction.
+func clusters(args []string) error {
+
+	fs := flag.NewFlagSet("clusters", flag.ContinueOnError)
+	lengths := fs.String("lengths", "64,64", "comma separated side lengths")
+	p := fs.Float64("p", 0.5927, "fraction of locations occupied")
+	samples := fs.Int("samples", 1000, "number of orthotopes to average over")
+	seed := fs.Int64("seed", 1, "seed every sample's seed is derived from")
+	workers := fs.Int("workers", 0, "samples built at once, or 0 for one per CPU")
+	out := fs.String("o", "", "file to write the table to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ls, err := parseLengths(*lengths)
+	if err != nil {
+		return err
+	}
+
+	r, err := newRunner(*seed, *workers)
+	if err != nil {
+		return err
+	}
+
+	d, err := r.ClusterSizes(context.Background(), ls, *p, *samples)
+	if err != nil {
+		return err
+	}
+
+	return write(*out, d.WriteTSV)
+}
+
+// directionOptions returns the Options for the -direction flag value name.
+func directionOptions(name string) ([]orth.Option, error) {
+
+	switch name {
+	case "undirected":
+		return nil, nil
+	case "forward":
+		return []orth.Option{orth.WithDirection(orth.Forward)}, nil
+	case "sideways":
+		return []orth.Option{orth.WithDirection(orth.ForwardSideways)}, nil
+	default:
+		return nil, fmt.Errorf("direction %q, want undirected, forward or sideways", name)
+	}
+}
+
+// newRunner returns a Runner seeded by seed running workers trials at once, or
+// one per CPU if workers is 0.
+func newRunner(seed int64, workers int) (*stats.Runner, error) {
+
+	opts := []stats.RunnerOption{stats.WithSeed(seed)}
+	if workers != 0 {
+		opts = append(opts, stats.WithWorkers(workers))
+	}
+
+	return stats.NewRunner(opts...)
+}
+
+// write calls writeTo on the file named out, or on stdout if out is empty.
+func write(out string, writeTo func(io.Writer) error) error {
+
+	if out == "" {
+		return writeTo(os.Stdout)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := writeTo(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// parseLengths parses comma separated side lengths such as "15,10".
+func parseLengths(s string) ([]int, error) {
+
+	var lengths []int
+	for _, f := range strings.Split(s, ",") {
+		l, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("lengths %q: %w", s, err)
+		}
+		lengths = append(lengths, l)
+	}
+
+	return lengths, nil
+}
+
 func main() {
 	log.Println("--- Starting")
 
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		log.Printf("exit: %v", err)
 	}
 
@@ -0,0 +1,56 @@
+package orth
+
+import "fmt"
+
+// FillBernoulli replaces every location's state in a single pass, making each
+// a bridge independently with probability p and leaving it unoccupied
+// otherwise. With a Field, the probability at each location is p times its
+// weight, capped at 1. It draws from src, or from the Orthotope's Source if src
+// is nil.
+func (o *Orthotope) FillBernoulli(p float64, src Source) error {
+
+	if !(p >= 0 && p <= 1) {
+		return fmt.Errorf("probability %v: %w", p, ErrInvalidOption)
+	}
+	if src == nil {
+		src = o.source
+	}
+
+	o.clear()
+	for i := range o.cells {
+		q := p
+		if o.weights != nil {
+			q *= o.weights[i]
+		}
+		if uniform(src) < q {
+			o.occupy(i)
+		}
+	}
+
+	return nil
+}
+
+// clear removes every bridge.
+func (o *Orthotope) clear() {
+
+	o.built = newBitset(len(o.cells))
+	for i := range o.cells {
+		o.cells[i] = int32(i)
+		o.position[i] = int32(i)
+	}
+	o.nBuilt = 0
+	if o.node != nil {
+		o.rebuild()
+	} else {
+		o.components = newForest(len(o.cells), o.countPeriodic())
+		o.tally = newTally(len(o.Lengths))
+	}
+	if o.weights != nil {
+		o.free = newFenwick(o.weights)
+	}
+	if o.directed != nil {
+		o.directed.reached = newBitset(len(o.cells))
+		o.directed.outlet = 0
+	}
+	o.record(OpClear, -1)
+}
@@ -0,0 +1,149 @@
+package orth
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestOrthotope_FillBernoulli(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		built   [][]int
+	}
+	type args struct {
+		p float64
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantBuilt [][]int
+		wantErr   error
+	}{
+		{
+			name: "p 0 clears",
+			fields: fields{
+				Lengths: []int{2, 2},
+				built:   [][]int{{0, 1}, {1, 1}},
+			},
+			args: args{p: 0},
+		},
+		{
+			name: "p 1 fills",
+			fields: fields{
+				Lengths: []int{2, 2},
+				built:   [][]int{{0, 1}},
+			},
+			args:      args{p: 1},
+			wantBuilt: [][]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+		},
+		{
+			name: "p 1 periodic",
+			fields: fields{
+				Lengths: []int{3, 2},
+				opts:    []Option{WithBoundaries(Periodic, Open)},
+			},
+			args:      args{p: 1},
+			wantBuilt: [][]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}, {2, 1}},
+		},
+		{
+			name: "negative p",
+			fields: fields{
+				Lengths: []int{2, 2},
+				built:   [][]int{{0, 1}},
+			},
+			args:      args{p: -0.5},
+			wantBuilt: [][]int{{0, 1}},
+			wantErr:   ErrInvalidOption,
+		},
+		{
+			name: "NaN p",
+			fields: fields{
+				Lengths: []int{2, 2},
+			},
+			args:    args{p: math.NaN()},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
+			err := o.FillBernoulli(tt.args.p, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.FillBernoulli() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := bridgeLocations(o); !reflect.DeepEqual(got, tt.wantBuilt) {
+				t.Errorf("Orthotope.FillBernoulli() -> %v, want %v", got, tt.wantBuilt)
+			}
+			checkInvariants(t, o)
+			checkComponents(t, o)
+		})
+	}
+}
+
+func TestOrthotope_FillBernoulli_random(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+		p       float64
+	}{
+		{name: "2D", lengths: []int{40, 30}, p: 0.6},
+		{name: "2D Moore", lengths: []int{40, 30}, opts: []Option{WithNeighborhood(Moore)}, p: 0.4},
+		{name: "3D periodic", lengths: []int{8, 7, 6}, opts: []Option{WithBoundaries(Periodic, Open, Periodic)}, p: 0.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.lengths, tt.opts)
+			src := rand.New(rand.NewSource(17))
+			for k := 0; k < 3; k++ {
+				if err := o.FillBernoulli(tt.p, src); err != nil {
+					t.Fatalf("Orthotope.FillBernoulli() error = %v", err)
+				}
+				checkInvariants(t, o)
+				checkComponents(t, o)
+
+				// The number of bridges is binomial; allow 5 standard deviations.
+				n := float64(len(o.cells))
+				sd := math.Sqrt(n * tt.p * (1 - tt.p))
+				if got := float64(o.nBuilt); math.Abs(got-n*tt.p) > 5*sd {
+					t.Errorf("Orthotope.FillBernoulli(%v) built %v of %v", tt.p, got, n)
+				}
+			}
+
+			// Building and demolishing afterwards keeps everything consistent.
+			for k := 0; k < 50; k++ {
+				if _, err := o.DemolishRandomFrom(src); err != nil {
+					t.Fatalf("Orthotope.DemolishRandomFrom() error = %v", err)
+				}
+				if _, err := o.BuildRandomFrom(src); err != nil {
+					t.Fatalf("Orthotope.BuildRandomFrom() error = %v", err)
+				}
+			}
+			checkInvariants(t, o)
+			checkComponents(t, o)
+		})
+	}
+}
+
+func TestOrthotope_FillBernoulli_reproducible(t *testing.T) {
+
+	fill := func(seed int64) [][]int {
+		o := newTestOrthotope(t, []int{10, 10}, []Option{WithSource(rand.New(rand.NewSource(seed)))})
+		if err := o.FillBernoulli(0.5, nil); err != nil {
+			t.Fatalf("Orthotope.FillBernoulli() error = %v", err)
+		}
+		return bridgeLocations(o)
+	}
+
+	if a, b := fill(3), fill(3); !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed filled differently")
+	}
+	if a, b := fill(3), fill(4); reflect.DeepEqual(a, b) {
+		t.Errorf("different seeds filled the same")
+	}
+}
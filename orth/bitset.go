@@ -0,0 +1,23 @@
+package orth
+
+// bitset is a fixed size set of non-negative integers packed 64 to a word.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+// get returns whether i is in the set.
+func (b bitset) get(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// set adds i to the set.
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+// clear removes i from the set.
+func (b bitset) clear(i int) {
+	b[i/64] &^= 1 << (uint(i) % 64)
+}
@@ -0,0 +1,52 @@
+package orth
+
+import "testing"
+
+func Test_bitset(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		set   []int
+		clear []int
+		want  []int
+	}{
+		{
+			name: "empty",
+			n:    10,
+		},
+		{
+			name: "word boundaries",
+			n:    130,
+			set:  []int{0, 63, 64, 127, 128, 129},
+			want: []int{0, 63, 64, 127, 128, 129},
+		},
+		{
+			name:  "set and clear",
+			n:     70,
+			set:   []int{1, 2, 65, 66},
+			clear: []int{2, 65, 3},
+			want:  []int{1, 66},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBitset(tt.n)
+			for _, i := range tt.set {
+				b.set(i)
+			}
+			for _, i := range tt.clear {
+				b.clear(i)
+			}
+
+			want := map[int]bool{}
+			for _, i := range tt.want {
+				want[i] = true
+			}
+			for i := 0; i < tt.n; i++ {
+				if got := b.get(i); got != want[i] {
+					t.Errorf("bitset.get(%d) = %v, want %v", i, got, want[i])
+				}
+			}
+		})
+	}
+}
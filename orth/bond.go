@@ -0,0 +1,227 @@
+package orth
+
+import "fmt"
+
+// BondOrthotope is an orthotope for bond percolation: every location is
+// present and the bonds between orthogonal neighbors open one at a time, so
+// locations are connected through open bonds rather than through bridges.
+//
+// The bond from location i up one step along dimension d, wrapping around a
+// Periodic dimension, has id i*N+d. An Open dimension has no bond up from its
+// n-1 face. bonds partitions the ids of every existing bond so that
+// bonds[:nOpen] are open and bonds[nOpen:] are not.
+//
+// Invariant:
+//   - open.get(b) iff position[b] < nOpen, for every existing bond b
+//   - bonds[position[b]] = b for every existing bond b
+//   - two locations share a set in components iff they are connected through
+//     open bonds
+//   - tally counts the sets in components spanning each dimension
+type BondOrthotope struct {
+	lattice
+	units      [][]int
+	steps      [][]int
+	rule       SpanningRule
+	source     Source
+	open       bitset
+	bonds      []int32
+	position   []int32
+	nOpen      int
+	components *forest
+	tally      tally
+}
+
+// NewBondOrthotope returns a BondOrthotope with side lengths lengths and every
+// bond closed. It takes the same Options as New, except that the Neighborhood
+// must be VonNeumann, since bonds only join orthogonal neighbors.
+func NewBondOrthotope(lengths []int, opts ...Option) (*BondOrthotope, error) {
+
+	c := newConfig(opts)
+	l, err := newLattice(lengths, c.boundaries)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.neighborhood.stencil != nil || c.neighborhood.reach != VonNeumann.reach {
+		return nil, fmt.Errorf("neighborhood %v, bonds join %v neighbors: %w", c.neighborhood, VonNeumann, ErrInvalidOption)
+	}
+
+	if c.source == nil {
+		return nil, fmt.Errorf("nil source: %w", ErrInvalidOption)
+	}
+
+	if err := c.rule.validate(l.periodic); err != nil {
+		return nil, fmt.Errorf("spanning rule %v: %w", c.rule, err)
+	}
+
+	n := len(lengths)
+	if n > 0 && l.size > maxCells/n {
+		return nil, fmt.Errorf("lengths %v exceed %d bonds: %w", lengths, maxCells, ErrTooLarge)
+	}
+
+	units := make([][]int, n)
+	for d := range units {
+		units[d] = make([]int, n)
+		units[d][d] = 1
+	}
+
+	o := &BondOrthotope{
+		lattice:    l,
+		units:      units,
+		steps:      l.periodicSteps(units),
+		rule:       c.rule,
+		source:     c.source,
+		open:       newBitset(l.size * n),
+		position:   make([]int32, l.size*n),
+		components: newForest(l.size, l.countPeriodic()),
+		tally:      newTally(n),
+	}
+
+	var scratch [8]int
+	for i := 0; i < l.size; i++ {
+		locs := o.appendCoords(scratch[:0], i)
+		for d := range units {
+			if _, ok := o.step(i, locs, units[d]); !ok {
+				continue
+			}
+			b := i*n + d
+			o.position[b] = int32(len(o.bonds))
+			o.bonds = append(o.bonds, int32(b))
+		}
+
+		// Every location starts as a set of its own.
+		o.components.reset(i, o.faces(i))
+		o.count(i, 1)
+	}
+
+	return o, nil
+}
+
+// OpenBond opens the bond between the adjacent locations a and b even if it is
+// already open. Around a Periodic dimension of length 2, where two bonds join
+// a and b, it is the one going up from a.
+func (o *BondOrthotope) OpenBond(a, b []int) error {
+
+	bond, err := o.bond(a, b)
+	if err != nil {
+		return err
+	}
+
+	if o.open.get(bond) {
+		return nil
+	}
+	o.occupy(bond)
+
+	return nil
+}
+
+// OpenRandomBond opens a closed bond, chosen uniformly by the BondOrthotope's
+// Source, and returns the locations it joins.
+func (o *BondOrthotope) OpenRandomBond() ([]int, []int, error) {
+	return o.OpenRandomBondFrom(o.source)
+}
+
+// OpenRandomBondFrom opens a closed bond, chosen uniformly by src, and returns
+// the locations it joins.
+func (o *BondOrthotope) OpenRandomBondFrom(src Source) ([]int, []int, error) {
+
+	closed := len(o.bonds) - o.nOpen
+	if closed == 0 {
+		return []int{}, []int{}, fmt.Errorf("no more closed bonds to open: %w", ErrInternalState)
+	}
+
+	// Select random closed bond
+	bond := int(o.bonds[o.nOpen+src.Intn(closed)])
+	a, b := o.ends(bond)
+	if o.open.get(bond) {
+		return []int{}, []int{}, fmt.Errorf("bond %v-%v in open bonds: %w", o.coords(a), o.coords(b), ErrInternalState)
+	}
+	o.occupy(bond)
+
+	return o.coords(a), o.coords(b), nil
+}
+
+// BondOpen returns whether the bond between the adjacent locations a and b is
+// open.
+func (o *BondOrthotope) BondOpen(a, b []int) (bool, error) {
+
+	bond, err := o.bond(a, b)
+	if err != nil {
+		return false, err
+	}
+
+	return o.open.get(bond), nil
+}
+
+// BridgeComplete returns true if the open bonds meet the BondOrthotope's
+// SpanningRule. By default that is a connected path of open bonds from 0 to
+// o.Lengths[0]-1 along the 1st dimension.
+func (o *BondOrthotope) BridgeComplete() (bool, error) {
+	return o.rule.complete(o.periodic, o.tally.axes(), o.tally.all > 0), nil
+}
+
+// SpanningAxes returns, in increasing order, the dimensions spanned by some
+// set of locations connected through open bonds, whatever the
+// BondOrthotope's SpanningRule.
+func (o *BondOrthotope) SpanningAxes() []int {
+	return o.tally.list()
+}
+
+// bond returns the id of the bond between the locations a and b.
+func (o *BondOrthotope) bond(a, b []int) (int, error) {
+
+	if !o.hasCell(a...) || !o.hasCell(b...) {
+		return 0, fmt.Errorf("bond %v-%v outside bounds limits %v: %w", a, b, o.Lengths, ErrOutOfBounds)
+	}
+
+	ia, ib := o.index(a...), o.index(b...)
+	for _, e := range [][2]int{{ia, ib}, {ib, ia}} {
+		locs := o.coords(e[0])
+		for d, unit := range o.units {
+			if j, ok := o.step(e[0], locs, unit); ok && j == e[1] {
+				return e[0]*len(o.Lengths) + d, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("bond %v-%v: %w", a, b, ErrNotAdjacent)
+}
+
+// ends returns the indices of the locations joined by bond, the one it goes up
+// from first.
+func (o *BondOrthotope) ends(bond int) (int, int) {
+
+	i, d := bond/len(o.Lengths), bond%len(o.Lengths)
+	j, _ := o.step(i, o.coords(i), o.units[d])
+
+	return i, j
+}
+
+// occupy opens the closed bond and joins the sets of the locations it joins.
+func (o *BondOrthotope) occupy(bond int) {
+
+	// Swap bond to the front of the closed bonds and grow the open prefix over it.
+	p := int(o.position[bond])
+	k := int(o.bonds[o.nOpen])
+	o.bonds[p], o.bonds[o.nOpen] = int32(k), int32(bond)
+	o.position[k], o.position[bond] = int32(p), int32(o.nOpen)
+	o.nOpen++
+	o.open.set(bond)
+
+	a, b := o.ends(bond)
+	ra := o.components.find(a)
+	rb := o.components.find(b)
+	o.count(ra, -1)
+	if rb != ra {
+		o.count(rb, -1)
+	}
+
+	r, winds := o.components.link(a, b, o.steps[bond%len(o.Lengths)])
+	o.components.mark(r, o.windFlags(winds))
+	o.count(r, 1)
+}
+
+// count adds delta to the tally of spanned dimensions for the set rooted at r.
+func (o *BondOrthotope) count(r int, delta int) {
+	o.tally.add(o.spans(o.components.flags(r)), delta)
+}
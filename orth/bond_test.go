@@ -0,0 +1,549 @@
+package orth
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// newTestBondOrthotope returns a BondOrthotope over lengths configured by opts
+// with the bonds between each pair in open opened.
+func newTestBondOrthotope(t *testing.T, lengths []int, opts []Option, open ...[2][]int) *BondOrthotope {
+	t.Helper()
+
+	o, err := NewBondOrthotope(lengths, opts...)
+	if err != nil {
+		t.Fatalf("NewBondOrthotope(%v) error = %v", lengths, err)
+	}
+	for _, b := range open {
+		if err := o.OpenBond(b[0], b[1]); err != nil {
+			t.Fatalf("BondOrthotope.OpenBond(%v, %v) error = %v", b[0], b[1], err)
+		}
+	}
+
+	return o
+}
+
+// checkBondInvariants fails t if o's storage disagrees with itself.
+func checkBondInvariants(t *testing.T, o *BondOrthotope) {
+	t.Helper()
+
+	var open int
+	for p, b := range o.bonds {
+		if int(o.position[b]) != p {
+			t.Fatalf("position[bonds[%d]] = %d, want %d", p, o.position[b], p)
+		}
+		ob := o.open.get(int(b))
+		if ob != (p < o.nOpen) {
+			t.Fatalf("open.get(%d) = %v but position %d, nOpen %d", b, ob, p, o.nOpen)
+		}
+		if ob {
+			open++
+		}
+	}
+	if open != o.nOpen {
+		t.Fatalf("%d open bonds, nOpen = %d", open, o.nOpen)
+	}
+}
+
+// bondSpannedBFS returns a bit for each dimension spanned by some set of
+// locations connected through open bonds, searching them all from scratch.
+func bondSpannedBFS(o *BondOrthotope) uint64 {
+
+	var spanned uint64
+	n := len(o.Lengths)
+	position := map[int][]int{}
+	for start := 0; start < o.size; start++ {
+		if _, ok := position[start]; ok {
+			continue
+		}
+
+		var flags uint64
+		position[start] = make([]int, n)
+		q := []int{start}
+		for len(q) > 0 {
+			cur := q[0]
+			q = q[1:]
+			flags |= o.faces(cur)
+
+			// Each open bond up from cur and each open bond down into it.
+			type move struct {
+				to    int
+				delta int
+				d     int
+			}
+			var moves []move
+			locs := o.coords(cur)
+			for d, unit := range o.units {
+				if j, ok := o.step(cur, locs, unit); ok && o.open.get(cur*n+d) {
+					moves = append(moves, move{j, 1, d})
+				}
+				down := make([]int, n)
+				down[d] = -1
+				if j, ok := o.step(cur, locs, down); ok && o.open.get(j*n+d) {
+					moves = append(moves, move{j, -1, d})
+				}
+			}
+
+			for _, m := range moves {
+				want := append([]int{}, position[cur]...)
+				want[m.d] += m.delta
+				got, seen := position[m.to]
+				if !seen {
+					position[m.to] = want
+					q = append(q, m.to)
+					continue
+				}
+				for d := range want {
+					if got[d] != want[d] {
+						flags |= 1 << (2*uint(n) + uint(d))
+					}
+				}
+			}
+		}
+		spanned |= o.spans(flags)
+	}
+
+	return spanned
+}
+
+func TestNewBondOrthotope(t *testing.T) {
+	type args struct {
+		lengths []int
+		opts    []Option
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantBonds int
+		wantErr   error
+	}{
+		{
+			name:      "2D",
+			args:      args{lengths: []int{3, 4}},
+			wantBonds: 2*4 + 3*3,
+		},
+		{
+			name:      "2D periodic",
+			args:      args{lengths: []int{3, 4}, opts: []Option{WithBoundaries(Open, Periodic)}},
+			wantBonds: 2*4 + 3*4,
+		},
+		{
+			name:      "3D",
+			args:      args{lengths: []int{2, 2, 2}},
+			wantBonds: 12,
+		},
+		{
+			name:      "empty",
+			args:      args{lengths: []int{}},
+			wantBonds: 0,
+		},
+		{
+			name:    "Moore",
+			args:    args{lengths: []int{3, 4}, opts: []Option{WithNeighborhood(Moore)}},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "stencil",
+			args:    args{lengths: []int{3, 4}, opts: []Option{WithNeighborhood(Stencil([]int{1, 0}))}},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "negative length",
+			args:    args{lengths: []int{3, -1}},
+			wantErr: ErrOutOfBounds,
+		},
+		{
+			name:    "too many bonds",
+			args:    args{lengths: []int{1 << 15, 1 << 15}},
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "boundaries mismatch",
+			args:    args{lengths: []int{3, 4}, opts: []Option{WithBoundaries(Open)}},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBondOrthotope(tt.args.lengths, tt.args.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewBondOrthotope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.bonds) != tt.wantBonds {
+				t.Errorf("NewBondOrthotope() has %d bonds, want %d", len(got.bonds), tt.wantBonds)
+			}
+			checkBondInvariants(t, got)
+		})
+	}
+}
+
+func TestBondOrthotope_OpenBond(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		open    [][2][]int
+	}
+	type args struct {
+		a []int
+		b []int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:   "up",
+			fields: fields{Lengths: []int{3, 4}},
+			args:   args{a: []int{1, 2}, b: []int{1, 3}},
+		},
+		{
+			name:   "down",
+			fields: fields{Lengths: []int{3, 4}},
+			args:   args{a: []int{2, 2}, b: []int{1, 2}},
+		},
+		{
+			name: "already open",
+			fields: fields{
+				Lengths: []int{3, 4},
+				open:    [][2][]int{{{1, 2}, {1, 3}}},
+			},
+			args: args{a: []int{1, 3}, b: []int{1, 2}},
+		},
+		{
+			name: "wrapping",
+			fields: fields{
+				Lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Periodic)},
+			},
+			args: args{a: []int{0, 3}, b: []int{0, 0}},
+		},
+		{
+			name:    "not wrapping",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{0, 3}, b: []int{0, 0}},
+			wantErr: ErrNotAdjacent,
+		},
+		{
+			name:    "diagonal",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{0, 0}, b: []int{1, 1}},
+			wantErr: ErrNotAdjacent,
+		},
+		{
+			name:    "same location",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{1, 1}, b: []int{1, 1}},
+			wantErr: ErrNotAdjacent,
+		},
+		{
+			name:    "out of bounds",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{2, 3}, b: []int{3, 3}},
+			wantErr: ErrOutOfBounds,
+		},
+		{
+			name:    "wrong dimensions",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{1}, b: []int{2}},
+			wantErr: ErrOutOfBounds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestBondOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.open...)
+			before := o.nOpen
+			err := o.OpenBond(tt.args.a, tt.args.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BondOrthotope.OpenBond() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			checkBondInvariants(t, o)
+			if err != nil {
+				if o.nOpen != before {
+					t.Errorf("BondOrthotope.OpenBond() opened a bond on error")
+				}
+				return
+			}
+			if got, _ := o.BondOpen(tt.args.a, tt.args.b); !got {
+				t.Errorf("BondOrthotope.BondOpen() = false after OpenBond()")
+			}
+			if got, _ := o.BondOpen(tt.args.b, tt.args.a); !got {
+				t.Errorf("BondOrthotope.BondOpen() reversed = false after OpenBond()")
+			}
+			if o.nOpen != 1 {
+				t.Errorf("BondOrthotope.OpenBond() -> %d open bonds, want 1", o.nOpen)
+			}
+		})
+	}
+}
+
+func TestBondOrthotope_BondOpen(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		open    [][2][]int
+	}
+	type args struct {
+		a []int
+		b []int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    bool
+		wantErr error
+	}{
+		{
+			name: "open",
+			fields: fields{
+				Lengths: []int{3, 4},
+				open:    [][2][]int{{{0, 0}, {1, 0}}},
+			},
+			args: args{a: []int{0, 0}, b: []int{1, 0}},
+			want: true,
+		},
+		{
+			name: "closed neighbor of open",
+			fields: fields{
+				Lengths: []int{3, 4},
+				open:    [][2][]int{{{0, 0}, {1, 0}}},
+			},
+			args: args{a: []int{0, 0}, b: []int{0, 1}},
+			want: false,
+		},
+		{
+			name: "periodic length 2 up from a",
+			fields: fields{
+				Lengths: []int{2},
+				opts:    []Option{WithBoundaries(Periodic)},
+				open:    [][2][]int{{{0}, {1}}},
+			},
+			args: args{a: []int{0}, b: []int{1}},
+			want: true,
+		},
+		{
+			name: "periodic length 2 other bond",
+			fields: fields{
+				Lengths: []int{2},
+				opts:    []Option{WithBoundaries(Periodic)},
+				open:    [][2][]int{{{0}, {1}}},
+			},
+			args: args{a: []int{1}, b: []int{0}},
+			want: false,
+		},
+		{
+			name:    "not adjacent",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{0, 0}, b: []int{2, 0}},
+			wantErr: ErrNotAdjacent,
+		},
+		{
+			name:    "out of bounds",
+			fields:  fields{Lengths: []int{3, 4}},
+			args:    args{a: []int{-1, 0}, b: []int{0, 0}},
+			wantErr: ErrOutOfBounds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestBondOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.open...)
+			got, err := o.BondOpen(tt.args.a, tt.args.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BondOrthotope.BondOpen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BondOrthotope.BondOpen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBondOrthotope_OpenRandomBond(t *testing.T) {
+
+	o := newTestBondOrthotope(t, []int{3, 3}, []Option{WithSource(rand.New(rand.NewSource(1)))})
+	for k := 0; k < 12; k++ {
+		a, b, err := o.OpenRandomBond()
+		if err != nil {
+			t.Fatalf("BondOrthotope.OpenRandomBond() error = %v", err)
+		}
+		if got, err := o.BondOpen(a, b); err != nil || !got {
+			t.Fatalf("BondOrthotope.OpenRandomBond() = %v, %v, BondOpen() = %v, %v", a, b, got, err)
+		}
+		checkBondInvariants(t, o)
+	}
+
+	if _, _, err := o.OpenRandomBond(); !errors.Is(err, ErrInternalState) {
+		t.Errorf("BondOrthotope.OpenRandomBond() with every bond open error = %v, want %v", err, ErrInternalState)
+	}
+	if got, err := o.BridgeComplete(); err != nil || !got {
+		t.Errorf("BondOrthotope.BridgeComplete() with every bond open = %v, %v", got, err)
+	}
+}
+
+func TestBondOrthotope_OpenRandomBond_reproducible(t *testing.T) {
+
+	order := func(seed int64) [][]int {
+		o := newTestBondOrthotope(t, []int{6, 5, 4}, []Option{WithSource(rand.New(rand.NewSource(seed)))})
+		var got [][]int
+		for o.nOpen < len(o.bonds) {
+			a, b, err := o.OpenRandomBond()
+			if err != nil {
+				t.Fatalf("BondOrthotope.OpenRandomBond() error = %v", err)
+			}
+			got = append(got, a, b)
+		}
+		return got
+	}
+
+	if a, b := order(7), order(7); !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed opened in different orders")
+	}
+	if a, b := order(7), order(8); reflect.DeepEqual(a, b) {
+		t.Errorf("different seeds opened in the same order")
+	}
+}
+
+func TestBondOrthotope_BridgeComplete(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		open    [][2][]int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   bool
+		axes   []int
+	}{
+		{
+			name:   "empty",
+			fields: fields{Lengths: []int{3, 4}},
+			want:   false,
+			axes:   []int{},
+		},
+		{
+			name: "path along 1st dimension",
+			fields: fields{
+				Lengths: []int{3, 4},
+				open: [][2][]int{
+					{{0, 1}, {1, 1}},
+					{{1, 1}, {1, 2}},
+					{{1, 2}, {2, 2}},
+				},
+			},
+			want: true,
+			axes: []int{0},
+		},
+		{
+			name: "gap",
+			fields: fields{
+				Lengths: []int{3, 4},
+				open: [][2][]int{
+					{{0, 1}, {1, 1}},
+					{{1, 2}, {2, 2}},
+				},
+			},
+			want: false,
+			axes: []int{},
+		},
+		{
+			name: "path along 2nd dimension",
+			fields: fields{
+				Lengths: []int{3, 3},
+				open: [][2][]int{
+					{{1, 0}, {1, 1}},
+					{{1, 1}, {1, 2}},
+				},
+			},
+			want: false,
+			axes: []int{1},
+		},
+		{
+			name: "thin dimension spans by itself",
+			fields: fields{
+				Lengths: []int{1, 3},
+			},
+			want: true,
+			axes: []int{0},
+		},
+		{
+			name: "wrapping",
+			fields: fields{
+				Lengths: []int{3},
+				opts:    []Option{WithBoundaries(Periodic), WithSpanningRule(SpanWrapping)},
+				open: [][2][]int{
+					{{0}, {1}},
+					{{1}, {2}},
+					{{2}, {0}},
+				},
+			},
+			want: true,
+			axes: []int{0},
+		},
+		{
+			name: "not yet wrapping",
+			fields: fields{
+				Lengths: []int{3},
+				opts:    []Option{WithBoundaries(Periodic), WithSpanningRule(SpanWrapping)},
+				open: [][2][]int{
+					{{0}, {1}},
+					{{2}, {0}},
+				},
+			},
+			want: false,
+			axes: []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestBondOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.open...)
+			got, err := o.BridgeComplete()
+			if err != nil {
+				t.Fatalf("BondOrthotope.BridgeComplete() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BondOrthotope.BridgeComplete() = %v, want %v", got, tt.want)
+			}
+			if axes := o.SpanningAxes(); !reflect.DeepEqual(axes, tt.axes) {
+				t.Errorf("BondOrthotope.SpanningAxes() = %v, want %v", axes, tt.axes)
+			}
+		})
+	}
+}
+
+func TestBondOrthotope_SpanningAxes_matchesBFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+	}{
+		{name: "1D", lengths: []int{6}},
+		{name: "2D", lengths: []int{12, 9}},
+		{name: "3D", lengths: []int{5, 4, 3}},
+		{name: "2D periodic", lengths: []int{12, 9}, opts: []Option{WithBoundaries(Open, Periodic)}},
+		{name: "2D torus", lengths: []int{6, 2}, opts: []Option{WithBoundaries(Periodic, Periodic)}},
+		{name: "3D periodic", lengths: []int{5, 4, 1}, opts: []Option{WithBoundaries(Open, Periodic, Periodic)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestBondOrthotope(t, tt.lengths, append(tt.opts, WithSource(rand.New(rand.NewSource(3)))))
+			for o.nOpen < len(o.bonds) {
+				a, b, err := o.OpenRandomBond()
+				if err != nil {
+					t.Fatalf("BondOrthotope.OpenRandomBond() error = %v", err)
+				}
+				if got, want := o.tally.axes(), bondSpannedBFS(o); got != want {
+					t.Fatalf("after opening %v-%v: spanned axes %b, bondSpannedBFS() = %b", a, b, got, want)
+				}
+			}
+			checkBondInvariants(t, o)
+		})
+	}
+}
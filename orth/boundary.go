@@ -0,0 +1,24 @@
+package orth
+
+// Boundary defines what lies past either end of a dimension.
+type Boundary int
+
+const (
+	// Open dimensions end in a hard wall: locations on the ends have no
+	// neighbors past them.
+	Open Boundary = iota
+	// Periodic dimensions wrap around, so location n-1 neighbors location 0.
+	Periodic
+)
+
+func (b Boundary) String() string {
+
+	switch b {
+	case Open:
+		return "open"
+	case Periodic:
+		return "periodic"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,162 @@
+package orth
+
+import "fmt"
+
+// Face is the 0 face, or if High the n-1 face, of dimension Axis.
+type Face struct {
+	Axis int
+	High bool
+}
+
+// Cluster describes one connected set of bridges.
+type Cluster struct {
+	// Label is the cluster's label, counted from 1.
+	Label int
+	// Size is the number of bridges in the cluster.
+	Size int
+	// Min and Max are the least and greatest location along each dimension,
+	// without regard to wrapping around Periodic dimensions.
+	Min []int
+	Max []int
+	// Faces lists the faces of Open dimensions the cluster touches.
+	Faces []Face
+	// Spans lists the dimensions the cluster spans, as in SpanningAxes.
+	Spans []int
+}
+
+// Labeling assigns every bridge of an Orthotope the label of its Cluster.
+type Labeling struct {
+	lengths  []int
+	strides  []int
+	labels   []int32
+	Clusters []Cluster
+}
+
+// Label returns the label of the cluster holding the bridge at locs, or 0 if
+// there is no bridge there.
+func (l *Labeling) Label(locs ...int) (int, error) {
+
+	if len(l.labels) == 0 || len(locs) != len(l.lengths) {
+		return 0, fmt.Errorf("location %v outside bounds limits %v: %w", locs, l.lengths, ErrOutOfBounds)
+	}
+
+	var i int
+	for d, loc := range locs {
+		if loc < 0 || loc >= l.lengths[d] {
+			return 0, fmt.Errorf("location %v outside bounds limits %v: %w", locs, l.lengths, ErrOutOfBounds)
+		}
+		i += loc * l.strides[d]
+	}
+
+	return int(l.labels[i]), nil
+}
+
+// Clusters labels the connected sets of bridges with a single Hoshen–Kopelman
+// pass in row-major order: each bridge takes the label of its neighbors that
+// come before it, with labels that turn out to meet merged in a union-find
+// over labels, then labels are renumbered from 1 in order of first appearance.
+func (o *Orthotope) Clusters() *Labeling {
+
+	labels := make([]int32, len(o.cells))
+
+	// equiv[l] is the label l was merged into, or l itself. Label 0 is unused.
+	equiv := []int32{0}
+	find := func(l int32) int32 {
+		for equiv[l] != l {
+			equiv[l] = equiv[equiv[l]]
+			l = equiv[l]
+		}
+		return l
+	}
+
+	var buf []int
+	for i := range labels {
+		if !o.built.get(i) {
+			continue
+		}
+
+		var label int32
+		buf = o.neighbors(i, buf[:0])
+		for _, n := range buf {
+			if n > i || labels[n] == 0 {
+				continue
+			}
+			nl := find(labels[n])
+			switch {
+			case label == 0:
+				label = nl
+			case nl < label:
+				equiv[label] = nl
+				label = nl
+			case nl > label:
+				equiv[nl] = label
+			}
+		}
+		if label == 0 {
+			label = int32(len(equiv))
+			equiv = append(equiv, label)
+		}
+		labels[i] = label
+	}
+
+	// Renumber the merged labels from 1 and describe each cluster.
+	final := make([]int32, len(equiv))
+	var clusters []Cluster
+	var flags []uint64
+	for i, label := range labels {
+		if label == 0 {
+			continue
+		}
+		root := find(label)
+		if final[root] == 0 {
+			clusters = append(clusters, Cluster{
+				Label: len(clusters) + 1,
+				Min:   o.coords(i),
+				Max:   o.coords(i),
+				Spans: []int{},
+			})
+			flags = append(flags, o.spans(o.components.flags(o.root(i))))
+			final[root] = int32(len(clusters))
+		}
+		labels[i] = final[root]
+
+		c := &clusters[final[root]-1]
+		c.Size++
+		for d, loc := range o.coords(i) {
+			if loc < c.Min[d] {
+				c.Min[d] = loc
+			}
+			if loc > c.Max[d] {
+				c.Max[d] = loc
+			}
+		}
+	}
+
+	for k := range clusters {
+		c := &clusters[k]
+		c.Faces = []Face{}
+		for d, p := range o.periodic {
+			if p {
+				continue
+			}
+			if c.Min[d] == 0 {
+				c.Faces = append(c.Faces, Face{Axis: d})
+			}
+			if c.Max[d] == o.Lengths[d]-1 {
+				c.Faces = append(c.Faces, Face{Axis: d, High: true})
+			}
+		}
+		for d := range o.Lengths {
+			if flags[k]&(1<<uint(d)) != 0 {
+				c.Spans = append(c.Spans, d)
+			}
+		}
+	}
+
+	return &Labeling{
+		lengths:  o.Lengths,
+		strides:  o.strides,
+		labels:   labels,
+		Clusters: clusters,
+	}
+}
@@ -0,0 +1,214 @@
+package orth
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestOrthotope_Clusters(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		built   [][]int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   []Cluster
+	}{
+		{
+			name: "empty",
+			fields: fields{
+				Lengths: []int{3, 4},
+			},
+			want: nil,
+		},
+		{
+			name: "2D",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built: [][]int{
+					{0, 0}, {0, 2},
+					{1, 0}, {1, 1},
+					{2, 1}, {2, 3},
+				},
+			},
+			want: []Cluster{
+				{
+					Label: 1, Size: 4, Min: []int{0, 0}, Max: []int{2, 1},
+					Faces: []Face{{Axis: 0}, {Axis: 0, High: true}, {Axis: 1}},
+					Spans: []int{0},
+				},
+				{
+					Label: 2, Size: 1, Min: []int{0, 2}, Max: []int{0, 2},
+					Faces: []Face{{Axis: 0}},
+					Spans: []int{},
+				},
+				{
+					Label: 3, Size: 1, Min: []int{2, 3}, Max: []int{2, 3},
+					Faces: []Face{{Axis: 0, High: true}, {Axis: 1, High: true}},
+					Spans: []int{},
+				},
+			},
+		},
+		{
+			name: "U shape merges labels",
+			fields: fields{
+				Lengths: []int{3, 3},
+				built: [][]int{
+					{0, 0}, {0, 2},
+					{1, 0}, {1, 2},
+					{2, 0}, {2, 1}, {2, 2},
+				},
+			},
+			want: []Cluster{
+				{
+					Label: 1, Size: 7, Min: []int{0, 0}, Max: []int{2, 2},
+					Faces: []Face{{Axis: 0}, {Axis: 0, High: true}, {Axis: 1}, {Axis: 1, High: true}},
+					Spans: []int{0, 1},
+				},
+			},
+		},
+		{
+			name: "Moore diagonal",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithNeighborhood(Moore)},
+				built:   [][]int{{0, 2}, {1, 1}, {2, 0}},
+			},
+			want: []Cluster{
+				{
+					Label: 1, Size: 3, Min: []int{0, 0}, Max: []int{2, 2},
+					Faces: []Face{{Axis: 0}, {Axis: 0, High: true}, {Axis: 1}, {Axis: 1, High: true}},
+					Spans: []int{0, 1},
+				},
+			},
+		},
+		{
+			name: "periodic wrap",
+			fields: fields{
+				Lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Periodic)},
+				built:   [][]int{{1, 0}, {1, 3}, {0, 2}},
+			},
+			want: []Cluster{
+				{
+					Label: 1, Size: 1, Min: []int{0, 2}, Max: []int{0, 2},
+					Faces: []Face{{Axis: 0}},
+					Spans: []int{},
+				},
+				{
+					Label: 2, Size: 2, Min: []int{1, 0}, Max: []int{1, 3},
+					Faces: []Face{},
+					Spans: []int{},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
+			got := o.Clusters()
+			if !reflect.DeepEqual(got.Clusters, tt.want) {
+				t.Errorf("Orthotope.Clusters() = %+v, want %+v", got.Clusters, tt.want)
+			}
+			for _, locs := range tt.fields.built {
+				label, err := got.Label(locs...)
+				if err != nil {
+					t.Fatalf("Labeling.Label(%v) error = %v", locs, err)
+				}
+				if label < 1 || label > len(got.Clusters) {
+					t.Errorf("Labeling.Label(%v) = %d, want a cluster label", locs, label)
+				}
+			}
+		})
+	}
+}
+
+func TestLabeling_Label(t *testing.T) {
+	o := newTestOrthotope(t, []int{3, 4}, nil, []int{0, 0}, []int{2, 3})
+	l := o.Clusters()
+	tests := []struct {
+		name    string
+		locs    []int
+		want    int
+		wantErr error
+	}{
+		{name: "first", locs: []int{0, 0}, want: 1},
+		{name: "second", locs: []int{2, 3}, want: 2},
+		{name: "empty", locs: []int{1, 1}, want: 0},
+		{name: "out of bounds", locs: []int{3, 0}, wantErr: ErrOutOfBounds},
+		{name: "missing dimension", locs: []int{0}, wantErr: ErrOutOfBounds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Label(tt.locs...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Labeling.Label() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Labeling.Label() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrthotope_Clusters_matchesComponents(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+	}{
+		{name: "2D", lengths: []int{20, 15}},
+		{name: "2D Moore torus", lengths: []int{12, 10}, opts: []Option{WithNeighborhood(Moore), WithBoundaries(Periodic, Periodic)}},
+		{name: "3D cylinder", lengths: []int{6, 5, 4}, opts: []Option{WithBoundaries(Open, Periodic, Open)}},
+		{name: "4D edge connected", lengths: []int{4, 3, 3, 4}, opts: []Option{WithNeighborhood(EdgeConnected)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(3))
+			o := newTestOrthotope(t, tt.lengths, tt.opts)
+			for i := range o.cells {
+				if rng.Float64() < 0.45 {
+					if err := o.Build(o.coords(i)...); err != nil {
+						t.Fatalf("Orthotope.Build() error = %v", err)
+					}
+				}
+			}
+
+			l := o.Clusters()
+			roots := map[int]int{}
+			sizes := map[int]int{}
+			for i := range o.cells {
+				label := int(l.labels[i])
+				if (label != 0) != o.built.get(i) {
+					t.Fatalf("label %d at %v, built %v", label, o.coords(i), o.built.get(i))
+				}
+				if label == 0 {
+					continue
+				}
+				r := o.root(i)
+				if got, ok := roots[label]; ok && got != r {
+					t.Fatalf("label %d split across sets", label)
+				}
+				roots[label] = r
+				sizes[label]++
+			}
+			if len(roots) != len(l.Clusters) {
+				t.Fatalf("%d labels used, %d clusters", len(roots), len(l.Clusters))
+			}
+			seen := map[int]bool{}
+			for label, r := range roots {
+				if seen[r] {
+					t.Fatalf("set of %v split across labels", o.coords(r))
+				}
+				seen[r] = true
+				if c := l.Clusters[label-1]; c.Size != sizes[label] || c.Size != int(o.components.size[r]) {
+					t.Errorf("cluster %d size = %d, counted %d, set size %d", label, c.Size, sizes[label], o.components.size[r])
+				}
+			}
+		})
+	}
+}
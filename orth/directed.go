@@ -0,0 +1,208 @@
+package orth
+
+import "fmt"
+
+// Direction defines which way paths of bridges may run for completion.
+type Direction int
+
+const (
+	// Undirected paths may move between neighbors in any direction, as the
+	// SpanningRule counts.
+	Undirected Direction = iota
+	// Forward paths run from the 0 to the n_1-1 face of the 1st dimension and
+	// may only move to neighbors further along it.
+	Forward
+	// ForwardSideways paths may also move to neighbors level with them along
+	// the 1st dimension, but never back.
+	ForwardSideways
+)
+
+func (d Direction) String() string {
+
+	switch d {
+	case Undirected:
+		return "undirected"
+	case Forward:
+		return "forward"
+	case ForwardSideways:
+		return "forward and sideways"
+	default:
+		return "unknown"
+	}
+}
+
+// directedState tracks, for a directed Orthotope, which bridges a path from
+// the 0 face can reach.
+//
+// Invariant:
+//   - reached.get(i) iff i is a bridge reached by a directed path of bridges
+//     from one on the 0 face of the 1st dimension
+//   - outlet is the number of reached bridges on its n_1-1 face
+type directedState struct {
+	// moves lists the offsets a path may take and back their negations.
+	moves   [][]int
+	back    [][]int
+	reached bitset
+	outlet  int
+}
+
+// newDirected returns the directed state for an empty orthotope over l with
+// neighbor offsets, or nil if d is Undirected.
+func newDirected(d Direction, l *lattice, offsets [][]int) (*directedState, error) {
+
+	switch d {
+	case Undirected:
+		return nil, nil
+	case Forward, ForwardSideways:
+	default:
+		return nil, fmt.Errorf("direction %d: %w", d, ErrInvalidOption)
+	}
+	if len(l.Lengths) == 0 || l.periodic[0] {
+		return nil, fmt.Errorf("%v paths need an %v 1st dimension: %w", d, Open, ErrInvalidOption)
+	}
+
+	s := &directedState{reached: newBitset(l.size)}
+	var forward bool
+	for _, offset := range offsets {
+		forward = forward || offset[0] > 0
+		if offset[0] > 0 || (d == ForwardSideways && offset[0] == 0) {
+			s.moves = append(s.moves, offset)
+			b := make([]int, len(offset))
+			for k, x := range offset {
+				b[k] = -x
+			}
+			s.back = append(s.back, b)
+		}
+	}
+	if !forward {
+		return nil, fmt.Errorf("%v paths need a neighbor further along the 1st dimension: %w", d, ErrInvalidOption)
+	}
+
+	return s, nil
+}
+
+// DirectedComplete returns true if a path of bridges following the
+// Orthotope's Direction runs from the 0 to the n_1-1 face of the 1st
+// dimension. It returns an error wrapping ErrInvalidOption if the Direction
+// is Undirected.
+func (o *Orthotope) DirectedComplete() (bool, error) {
+
+	if o.directed == nil {
+		return false, fmt.Errorf("%v orthotope: %w", Undirected, ErrInvalidOption)
+	}
+
+	return o.directed.outlet > 0, nil
+}
+
+// directedPath returns the locations of a shortest path of bridges following
+// the Orthotope's Direction from the 0 to the n_1-1 face of the 1st
+// dimension, in order, or an error wrapping ErrNoPath if there is none.
+func (o *Orthotope) directedPath() ([][]int, error) {
+
+	s := o.directed
+	if s.outlet == 0 {
+		return nil, fmt.Errorf("no directed path of bridges across %v: %w", o.Lengths, ErrNoPath)
+	}
+
+	// Breadth first search along the moves out of every bridge on the 0 face,
+	// recording where each location was reached from.
+	const unvisited, source = -1, -2
+	from := make([]int32, len(o.cells))
+	for i := range from {
+		from[i] = unvisited
+	}
+
+	var q []int
+	for _, c := range o.cells[:o.nBuilt] {
+		if i := int(c); i/o.strides[0] == 0 {
+			from[i] = source
+			q = append(q, i)
+		}
+	}
+
+	var scratch [8]int
+	for len(q) > 0 {
+		cur := q[0]
+		q = q[1:]
+
+		locs := o.appendCoords(scratch[:0], cur)
+		if locs[0] == o.Lengths[0]-1 {
+			return o.trace(from, cur), nil
+		}
+		for _, m := range s.moves {
+			n, ok := o.step(cur, locs, m)
+			if ok && o.built.get(n) && from[n] == unvisited {
+				from[n] = int32(cur)
+				q = append(q, n)
+			}
+		}
+	}
+
+	// Reaching the n_1-1 face without a path is against invariant.
+	return nil, fmt.Errorf("outlet reached without a path in %v: %w", o.Lengths, ErrInternalState)
+}
+
+// reach marks the newly built bridge at index i, and every bridge reachable
+// from it, as reached if a path from the 0 face gets to i.
+func (o *Orthotope) reach(i int) {
+
+	s := o.directed
+	var scratch [8]int
+	locs := o.appendCoords(scratch[:0], i)
+
+	if locs[0] == 0 {
+		o.spread(i)
+		return
+	}
+	for _, b := range s.back {
+		if j, ok := o.step(i, locs, b); ok && s.reached.get(j) {
+			o.spread(i)
+			return
+		}
+	}
+}
+
+// spread marks the bridge at index i and every unreached bridge reachable from
+// it as reached.
+func (o *Orthotope) spread(i int) {
+
+	s := o.directed
+	s.reached.set(i)
+	q := []int{i}
+	var scratch [8]int
+	for len(q) > 0 {
+		cur := q[len(q)-1]
+		q = q[:len(q)-1]
+		locs := o.appendCoords(scratch[:0], cur)
+		if locs[0] == o.Lengths[0]-1 {
+			s.outlet++
+		}
+		for _, m := range s.moves {
+			n, ok := o.step(cur, locs, m)
+			if ok && o.built.get(n) && !s.reached.get(n) {
+				s.reached.set(n)
+				q = append(q, n)
+			}
+		}
+	}
+}
+
+// unreach updates the reached bridges after the reached bridge at index i was
+// demolished. Bridges reached only through i are not known, so every path is
+// searched again from the 0 face.
+func (o *Orthotope) unreach(i int) {
+
+	s := o.directed
+	if !s.reached.get(i) {
+		return
+	}
+
+	s.reached = newBitset(len(o.cells))
+	s.outlet = 0
+	for _, c := range o.cells[:o.nBuilt] {
+		j := int(c)
+		if j/o.strides[0] == 0 && !s.reached.get(j) {
+			o.spread(j)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package orth
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// directedCompleteBFS answers DirectedComplete by searching every directed
+// path from the 0 face from scratch.
+func directedCompleteBFS(o *Orthotope) bool {
+
+	seen := map[int]bool{}
+	var q []int
+	for _, c := range o.cells[:o.nBuilt] {
+		if o.coords(int(c))[0] == 0 {
+			seen[int(c)] = true
+			q = append(q, int(c))
+		}
+	}
+	for len(q) > 0 {
+		cur := q[0]
+		q = q[1:]
+		locs := o.coords(cur)
+		if locs[0] == o.Lengths[0]-1 {
+			return true
+		}
+		for _, m := range o.directed.moves {
+			if n, ok := o.step(cur, locs, m); ok && o.built.get(n) && !seen[n] {
+				seen[n] = true
+				q = append(q, n)
+			}
+		}
+	}
+
+	return false
+}
+
+func TestOrthotope_DirectedComplete(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		built   [][]int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    bool
+		wantErr error
+	}{
+		{
+			name: "straight",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithDirection(Forward)},
+				built:   [][]int{{0, 1}, {1, 1}, {2, 1}},
+			},
+			want: true,
+		},
+		{
+			name: "sideways step not allowed",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithDirection(Forward)},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+			},
+			want: false,
+		},
+		{
+			name: "sideways step allowed",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithDirection(ForwardSideways)},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+			},
+			want: true,
+		},
+		{
+			name: "sideways then forward",
+			fields: fields{
+				Lengths: []int{4, 3},
+				opts:    []Option{WithDirection(ForwardSideways)},
+				built: [][]int{
+					{0, 0}, {1, 0}, {2, 0},
+					{2, 1}, {2, 2},
+					{1, 2},
+					{3, 1},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "only by doubling back",
+			fields: fields{
+				Lengths: []int{4, 5},
+				opts:    []Option{WithDirection(ForwardSideways)},
+				built: [][]int{
+					{0, 0},
+					{1, 0}, {1, 2}, {1, 3}, {1, 4},
+					{2, 0}, {2, 1}, {2, 2}, {2, 4},
+					{3, 4},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "forward diagonals",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithDirection(Forward), WithNeighborhood(Moore)},
+				built:   [][]int{{0, 0}, {1, 1}, {2, 0}},
+			},
+			want: true,
+		},
+		{
+			name: "forward jumps",
+			fields: fields{
+				Lengths: []int{5, 1},
+				opts:    []Option{WithDirection(Forward), WithNeighborhood(Stencil([]int{2, 0}))},
+				built:   [][]int{{0, 0}, {2, 0}, {4, 0}},
+			},
+			want: true,
+		},
+		{
+			name: "sideways wraps around periodic dimension",
+			fields: fields{
+				Lengths: []int{2, 4},
+				opts:    []Option{WithDirection(ForwardSideways), WithBoundaries(Open, Periodic)},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 3}},
+			},
+			want: true,
+		},
+		{
+			name: "undirected",
+			fields: fields{
+				Lengths: []int{3, 3},
+			},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
+			got, err := o.DirectedComplete()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.DirectedComplete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Orthotope.DirectedComplete() = %v, want %v", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if complete, _ := o.BridgeComplete(); complete != got {
+				t.Errorf("Orthotope.BridgeComplete() = %v, DirectedComplete() = %v", complete, got)
+			}
+		})
+	}
+}
+
+func TestOrthotope_SpanningPath_directed(t *testing.T) {
+
+	// From (0, 0) the shortest path steps back from (2, 2) to (1, 2) to get
+	// round the gap at (2, 3). The only directed path runs the long way round
+	// from (0, 13).
+	detour := [][]int{
+		{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2},
+		{1, 2}, {1, 3}, {1, 4}, {2, 4}, {3, 4},
+		{0, 13}, {1, 13}, {2, 13}, {2, 12}, {2, 11}, {2, 10}, {2, 9}, {2, 8}, {2, 7}, {2, 6}, {2, 5},
+	}
+
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		built   [][]int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    [][]int
+		wantErr error
+	}{
+		{
+			name: "undirected steps back",
+			fields: fields{
+				Lengths: []int{4, 14},
+				built:   detour,
+			},
+			want: [][]int{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2}, {1, 2}, {1, 3}, {1, 4}, {2, 4}, {3, 4}},
+		},
+		{
+			name: "directed goes round",
+			fields: fields{
+				Lengths: []int{4, 14},
+				opts:    []Option{WithDirection(ForwardSideways)},
+				built:   detour,
+			},
+			want: [][]int{
+				{0, 13}, {1, 13}, {2, 13}, {2, 12}, {2, 11}, {2, 10}, {2, 9}, {2, 8}, {2, 7}, {2, 6}, {2, 5}, {2, 4}, {3, 4},
+			},
+		},
+		{
+			name: "forward only",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithDirection(Forward), WithNeighborhood(Moore)},
+				built:   [][]int{{0, 0}, {0, 1}, {1, 2}, {2, 1}},
+			},
+			want: [][]int{{0, 1}, {1, 2}, {2, 1}},
+		},
+		{
+			name: "undirected complete only",
+			fields: fields{
+				Lengths: []int{3, 2},
+				opts:    []Option{WithDirection(Forward)},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+			},
+			wantErr: ErrNoPath,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
+			got, err := o.SpanningPath()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.SpanningPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.SpanningPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_direction(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "forward", opts: []Option{WithDirection(Forward)}},
+		{name: "periodic 1st dimension", opts: []Option{WithDirection(Forward), WithBoundaries(Periodic, Open)}, wantErr: ErrInvalidOption},
+		{name: "unknown", opts: []Option{WithDirection(Direction(7))}, wantErr: ErrInvalidOption},
+		{name: "stride 2 stencil", opts: []Option{WithDirection(Forward), WithNeighborhood(Stencil([]int{2, 0}))}},
+		{name: "default rule", opts: []Option{WithDirection(Forward), WithSpanningRule(SpanAxis(0))}},
+		{name: "other rule", opts: []Option{WithSpanningRule(SpanAll), WithDirection(Forward)}, wantErr: ErrInvalidOption},
+		{name: "undirected with rule", opts: []Option{WithDirection(Undirected), WithSpanningRule(SpanAny)}},
+		{name: "no forward neighbor", opts: []Option{WithDirection(ForwardSideways), WithNeighborhood(Stencil([]int{0, 1}))}, wantErr: ErrInvalidOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]int{3, 3}, tt.opts...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrthotope_DirectedComplete_matchesBFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+	}{
+		{name: "2D forward", lengths: []int{12, 8}, opts: []Option{WithDirection(Forward)}},
+		{name: "2D forward Moore", lengths: []int{12, 8}, opts: []Option{WithDirection(Forward), WithNeighborhood(Moore)}},
+		{name: "2D sideways", lengths: []int{12, 8}, opts: []Option{WithDirection(ForwardSideways)}},
+		{name: "3D sideways periodic", lengths: []int{6, 4, 4}, opts: []Option{WithDirection(ForwardSideways), WithBoundaries(Open, Periodic, Periodic)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := rand.New(rand.NewSource(8))
+			o := newTestOrthotope(t, tt.lengths, append(tt.opts, WithSource(src)))
+			for k := 0; k < 3*len(o.cells); k++ {
+				var err error
+				if o.nBuilt > 0 && src.Intn(3) == 0 {
+					_, err = o.DemolishRandom()
+				} else if o.nBuilt < len(o.cells) {
+					_, err = o.BuildRandom()
+				}
+				if err != nil {
+					t.Fatalf("step %d error = %v", k, err)
+				}
+				got, _ := o.DirectedComplete()
+				if want := directedCompleteBFS(o); got != want {
+					t.Fatalf("step %d: Orthotope.DirectedComplete() = %v, directedCompleteBFS() = %v", k, got, want)
+				}
+			}
+
+			if err := o.FillBernoulli(0.7, nil); err != nil {
+				t.Fatalf("Orthotope.FillBernoulli() error = %v", err)
+			}
+			if got, _ := o.DirectedComplete(); got != directedCompleteBFS(o) {
+				t.Errorf("after FillBernoulli: Orthotope.DirectedComplete() = %v, want %v", got, !got)
+			}
+			checkInvariants(t, o)
+		})
+	}
+}
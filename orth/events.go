@@ -0,0 +1,188 @@
+package orth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Op is the change an Event made to an Orthotope.
+type Op int
+
+const (
+	// OpBuild placed a bridge at an unoccupied location.
+	OpBuild Op = iota
+	// OpDemolish removed the bridge at a location.
+	OpDemolish
+	// OpClear removed every bridge.
+	OpClear
+)
+
+func (op Op) String() string {
+
+	switch op {
+	case OpBuild:
+		return "build"
+	case OpDemolish:
+		return "demolish"
+	case OpClear:
+		return "clear"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes op by name.
+func (op Op) MarshalText() ([]byte, error) {
+
+	if op < OpBuild || op > OpClear {
+		return nil, fmt.Errorf("op %d: %w", int(op), ErrInvalidOption)
+	}
+
+	return []byte(op.String()), nil
+}
+
+// UnmarshalText decodes op from its name.
+func (op *Op) UnmarshalText(text []byte) error {
+
+	for o := OpBuild; o <= OpClear; o++ {
+		if string(text) == o.String() {
+			*op = o
+			return nil
+		}
+	}
+
+	return fmt.Errorf("op %q: %w", text, ErrInvalidOption)
+}
+
+// Event records one change to an Orthotope. Changes are recorded, not calls:
+// building over a bridge records nothing, and FillBernoulli records a clear
+// followed by a build for each bridge.
+type Event struct {
+	// Seq numbers the events of a log from 0.
+	Seq int `json:"seq"`
+	Op  Op  `json:"op"`
+	// Locs is the location changed, or nil for OpClear.
+	Locs []int     `json:"locs,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// Events returns a copy of the Orthotope's event log, or nil if it was not
+// created WithEventLog.
+func (o *Orthotope) Events() []Event {
+
+	if !o.logEvents {
+		return nil
+	}
+
+	return append([]Event{}, o.events...)
+}
+
+// record appends an event of op at index i, or of no location if i < 0, to the
+// event log if there is one.
+func (o *Orthotope) record(op Op, i int) {
+
+	if !o.logEvents {
+		return
+	}
+
+	e := Event{Seq: len(o.events), Op: op, Time: time.Now()}
+	if i >= 0 {
+		e.Locs = o.coords(i)
+	}
+	o.events = append(o.events, e)
+}
+
+// Replay returns a new Orthotope with side lengths lengths configured by opts,
+// after applying the first step events of events to it, or all of them if step
+// is past the end. The events must be numbered in order from 0 and each must
+// change the Orthotope as it did when recorded. It returns an error wrapping
+// ErrInvalidOption if step is negative.
+func Replay(lengths []int, events []Event, step int, opts ...Option) (*Orthotope, error) {
+
+	if step < 0 {
+		return nil, fmt.Errorf("step %d: %w", step, ErrInvalidOption)
+	}
+
+	o, err := New(lengths, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if step > len(events) {
+		step = len(events)
+	}
+	for k, e := range events[:step] {
+		if e.Seq != k {
+			return nil, fmt.Errorf("event %d numbered %d: %w", k, e.Seq, ErrInvalidOption)
+		}
+		if err := o.apply(e); err != nil {
+			return nil, fmt.Errorf("event %d: %w", k, err)
+		}
+	}
+
+	return o, nil
+}
+
+// apply makes the change recorded by e.
+func (o *Orthotope) apply(e Event) error {
+
+	if e.Op == OpClear {
+		o.clear()
+		return nil
+	}
+
+	if !o.hasCell(e.Locs...) {
+		return fmt.Errorf("location %v outside bounds limits %v: %w", e.Locs, o.Lengths, ErrOutOfBounds)
+	}
+	i := o.index(e.Locs...)
+
+	switch e.Op {
+	case OpBuild:
+		if o.built.get(i) {
+			return fmt.Errorf("%v at %v: %w", e.Op, e.Locs, ErrOccupied)
+		}
+		o.occupy(i)
+	case OpDemolish:
+		if !o.built.get(i) {
+			return fmt.Errorf("%v at %v with no bridge: %w", e.Op, e.Locs, ErrInternalState)
+		}
+		o.vacate(i)
+	default:
+		return fmt.Errorf("op %d: %w", int(e.Op), ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// WriteEvents writes events to w as JSON, one event per line.
+func WriteEvents(w io.Writer, events []Event) error {
+
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadEvents reads events written by WriteEvents from r.
+func ReadEvents(r io.Reader) ([]Event, error) {
+
+	var events []Event
+	dec := json.NewDecoder(r)
+	for {
+		var e Event
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+}
@@ -0,0 +1,210 @@
+package orth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// describe returns each event as its sequence number, op and location,
+// leaving out its time.
+func describe(events []Event) []string {
+
+	var got []string
+	for _, e := range events {
+		got = append(got, fmt.Sprint(e.Seq, " ", e.Op, " ", e.Locs))
+	}
+
+	return got
+}
+
+func TestOrthotope_Events(t *testing.T) {
+
+	o := newTestOrthotope(t, []int{2, 2}, []Option{WithEventLog(), WithSource(rand.New(rand.NewSource(1)))})
+	start := time.Now()
+	steps := []func() error{
+		func() error { return o.Build(0, 1) },
+		func() error { return o.Build(0, 1) },
+		func() error { return o.Build(1, 1) },
+		func() error { return o.Demolish(0, 1) },
+		func() error { return o.Demolish(0, 0) },
+		func() error { return o.FillBernoulli(1, nil) },
+	}
+	for k, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d error = %v", k, err)
+		}
+	}
+
+	events := o.Events()
+	want := []string{
+		"0 build [0 1]",
+		"1 build [1 1]",
+		"2 demolish [0 1]",
+		"3 clear []",
+		"4 build [0 0]",
+		"5 build [0 1]",
+		"6 build [1 0]",
+		"7 build [1 1]",
+	}
+	if got := describe(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Orthotope.Events() = %q, want %q", got, want)
+	}
+	for k, e := range events {
+		if e.Time.Before(start) || (k > 0 && e.Time.Before(events[k-1].Time)) {
+			t.Errorf("Orthotope.Events() event %d at %v out of order", k, e.Time)
+		}
+	}
+
+	// Events returns a copy.
+	events[0].Op = OpClear
+	if o.Events()[0].Op != OpBuild {
+		t.Errorf("Orthotope.Events() shares its log with the caller")
+	}
+
+	if got := newTestOrthotope(t, []int{2, 2}, nil, []int{0, 0}).Events(); got != nil {
+		t.Errorf("Orthotope.Events() without WithEventLog = %v, want nil", got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+
+	opts := []Option{WithBoundaries(Open, Periodic), WithEventLog(), WithSource(rand.New(rand.NewSource(2)))}
+	o := newTestOrthotope(t, []int{6, 5}, opts)
+
+	// The bridges after each call, by the number of events then.
+	want := map[int][][]int{0: nil}
+	src := rand.New(rand.NewSource(3))
+	for k := 0; k < 60; k++ {
+		var err error
+		switch {
+		case k == 30:
+			err = o.FillBernoulli(0.5, nil)
+		case o.nBuilt > 0 && src.Intn(3) == 0:
+			_, err = o.DemolishRandom()
+		default:
+			_, err = o.BuildRandom()
+		}
+		if err != nil {
+			t.Fatalf("step %d error = %v", k, err)
+		}
+		want[len(o.Events())] = bridgeLocations(o)
+	}
+
+	events := o.Events()
+	for step, locs := range want {
+		got, err := Replay(o.Lengths, events, step, WithBoundaries(Open, Periodic))
+		if err != nil {
+			t.Fatalf("Replay(%d) error = %v", step, err)
+		}
+		if !reflect.DeepEqual(bridgeLocations(got), locs) {
+			t.Errorf("Replay(%d) -> %v, want %v", step, bridgeLocations(got), locs)
+		}
+		checkInvariants(t, got)
+		checkComponents(t, got)
+	}
+
+	got, err := Replay(o.Lengths, events, len(events)+10, WithBoundaries(Open, Periodic))
+	if err != nil {
+		t.Fatalf("Replay() past the end error = %v", err)
+	}
+	if !reflect.DeepEqual(bridgeLocations(got), bridgeLocations(o)) {
+		t.Errorf("Replay() past the end -> %v, want %v", bridgeLocations(got), bridgeLocations(o))
+	}
+}
+
+func TestReplay_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []Event
+		step    int
+		wantErr error
+	}{
+		{
+			name:    "out of order",
+			events:  []Event{{Seq: 1, Op: OpBuild, Locs: []int{0, 0}}},
+			step:    1,
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "build over bridge",
+			events: []Event{
+				{Seq: 0, Op: OpBuild, Locs: []int{0, 0}},
+				{Seq: 1, Op: OpBuild, Locs: []int{0, 0}},
+			},
+			step:    2,
+			wantErr: ErrOccupied,
+		},
+		{
+			name:    "demolish nothing",
+			events:  []Event{{Seq: 0, Op: OpDemolish, Locs: []int{1, 1}}},
+			step:    1,
+			wantErr: ErrInternalState,
+		},
+		{
+			name:    "out of bounds",
+			events:  []Event{{Seq: 0, Op: OpBuild, Locs: []int{2, 0}}},
+			step:    1,
+			wantErr: ErrOutOfBounds,
+		},
+		{
+			name:    "unknown op",
+			events:  []Event{{Seq: 0, Op: Op(9), Locs: []int{0, 0}}},
+			step:    1,
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "negative step",
+			events:  []Event{{Seq: 0, Op: OpBuild, Locs: []int{0, 0}}},
+			step:    -1,
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Replay([]int{2, 2}, tt.events, tt.step); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Replay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteEvents(t *testing.T) {
+
+	events := []Event{
+		{Seq: 0, Op: OpBuild, Locs: []int{1, 2}, Time: time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)},
+		{Seq: 1, Op: OpClear, Time: time.Date(2020, 1, 2, 3, 4, 6, 0, time.UTC)},
+		{Seq: 2, Op: OpDemolish, Locs: []int{0, 0}, Time: time.Date(2020, 1, 2, 3, 4, 7, 0, time.UTC)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEvents(&buf, events); err != nil {
+		t.Fatalf("WriteEvents() error = %v", err)
+	}
+	want := `{"seq":0,"op":"build","locs":[1,2],"time":"2020-01-02T03:04:05.000000006Z"}
+{"seq":1,"op":"clear","time":"2020-01-02T03:04:06Z"}
+{"seq":2,"op":"demolish","locs":[0,0],"time":"2020-01-02T03:04:07Z"}
+`
+	if buf.String() != want {
+		t.Errorf("WriteEvents() = %s, want %s", buf.String(), want)
+	}
+
+	got, err := ReadEvents(&buf)
+	if err != nil {
+		t.Fatalf("ReadEvents() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, events) {
+		t.Errorf("ReadEvents() = %v, want %v", got, events)
+	}
+
+	if _, err := ReadEvents(bytes.NewBufferString(`{"seq":0,"op":"explode"}`)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("ReadEvents() of unknown op error = %v, want %v", err, ErrInvalidOption)
+	}
+	if err := WriteEvents(&buf, []Event{{Op: Op(9)}}); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("WriteEvents() of unknown op error = %v, want %v", err, ErrInvalidOption)
+	}
+}
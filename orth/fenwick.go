@@ -0,0 +1,59 @@
+package orth
+
+// fenwick is a binary indexed tree over non-negative weights of the integers
+// [0, n), supporting weight updates, the total and finding the integer at a
+// given point of the cumulative weight, each in O(log n).
+type fenwick []float64
+
+// newFenwick returns a fenwick over weights, built in O(n).
+func newFenwick(weights []float64) fenwick {
+
+	f := make(fenwick, len(weights)+1)
+	for i, w := range weights {
+		f[i+1] += w
+		if j := (i + 1) + (i+1)&-(i+1); j < len(f) {
+			f[j] += f[i+1]
+		}
+	}
+
+	return f
+}
+
+// add adds delta to the weight of i.
+func (f fenwick) add(i int, delta float64) {
+	for j := i + 1; j < len(f); j += j & -j {
+		f[j] += delta
+	}
+}
+
+// total returns the sum of every weight.
+func (f fenwick) total() float64 {
+
+	var sum float64
+	for j := len(f) - 1; j > 0; j -= j & -j {
+		sum += f[j]
+	}
+
+	return sum
+}
+
+// search returns the least i whose weight, added to those before it, exceeds
+// u, or n if there is none.
+func (f fenwick) search(u float64) int {
+
+	step := 1
+	for step*2 < len(f) {
+		step *= 2
+	}
+
+	// pos is the largest prefix found with weight at most u.
+	var pos int
+	for ; step > 0; step /= 2 {
+		if next := pos + step; next < len(f) && f[next] <= u {
+			pos = next
+			u -= f[next]
+		}
+	}
+
+	return pos
+}
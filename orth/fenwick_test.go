@@ -0,0 +1,93 @@
+package orth
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func Test_fenwick(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []float64
+		add     map[int]float64
+		total   float64
+		search  map[float64]int
+	}{
+		{
+			name:    "empty",
+			weights: []float64{},
+			total:   0,
+			search:  map[float64]int{0: 0},
+		},
+		{
+			name:    "uniform",
+			weights: []float64{1, 1, 1, 1, 1},
+			total:   5,
+			search:  map[float64]int{0: 0, 0.5: 0, 1: 1, 2.5: 2, 4.99: 4, 5: 5},
+		},
+		{
+			name:    "zero weights skipped",
+			weights: []float64{0, 2, 0, 0, 3, 0},
+			total:   5,
+			search:  map[float64]int{0: 1, 1.9: 1, 2: 4, 4.9: 4},
+		},
+		{
+			name:    "updated",
+			weights: []float64{1, 2, 3, 4, 5, 6, 7},
+			add:     map[int]float64{1: -2, 6: -7, 0: 1},
+			total:   20,
+			search:  map[float64]int{0: 0, 1.9: 0, 2: 2, 4.9: 2, 5: 3, 19.9: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFenwick(tt.weights)
+			for i, d := range tt.add {
+				f.add(i, d)
+			}
+			if got := f.total(); got != tt.total {
+				t.Errorf("fenwick.total() = %v, want %v", got, tt.total)
+			}
+			for u, want := range tt.search {
+				if got := f.search(u); got != want {
+					t.Errorf("fenwick.search(%v) = %v, want %v", u, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_fenwick_matchesScan(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	weights := make([]float64, 37)
+	for i := range weights {
+		weights[i] = float64(rng.Intn(4))
+	}
+	f := newFenwick(weights)
+	for k := 0; k < 200; k++ {
+		i := rng.Intn(len(weights))
+		d := float64(rng.Intn(4)) - weights[i]
+		weights[i] += d
+		f.add(i, d)
+
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		if f.total() != total {
+			t.Fatalf("fenwick.total() = %v, want %v", f.total(), total)
+		}
+		u := float64(rng.Intn(int(total) + 1))
+		want, sum := len(weights), 0.0
+		for j, w := range weights {
+			if sum += w; sum > u {
+				want = j
+				break
+			}
+		}
+		if got := f.search(u); got != want {
+			t.Fatalf("fenwick.search(%v) = %v, want %v over %v", u, got, want, weights)
+		}
+	}
+}
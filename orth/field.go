@@ -0,0 +1,76 @@
+package orth
+
+import (
+	"fmt"
+	"math"
+)
+
+// Field gives the weight of the location locs in an orthotope with side
+// lengths lengths. Weights must be finite and non-negative, and New returns
+// an error for a Field giving any other or panicking. BuildRandom picks
+// unoccupied locations with probability proportional to their weight, and
+// FillBernoulli scales its probability by it.
+type Field func(locs, lengths []int) float64
+
+// Gradient returns a Field varying linearly along dimension axis, from from
+// at location 0 to to at location n-1. It weighs every location NaN in an
+// orthotope without dimension axis.
+func Gradient(axis int, from, to float64) Field {
+	return func(locs, lengths []int) float64 {
+		if axis < 0 || axis >= len(lengths) {
+			return math.NaN()
+		}
+		if lengths[axis] < 2 {
+			return from
+		}
+		return from + (to-from)*float64(locs[axis])/float64(lengths[axis]-1)
+	}
+}
+
+// Layered returns a Field of len(values) layers of equal thickness along
+// dimension axis, the kth layer from location 0 having weight values[k]. It
+// weighs every location NaN if there are no values or no dimension axis.
+func Layered(axis int, values ...float64) Field {
+	return func(locs, lengths []int) float64 {
+		if len(values) == 0 || axis < 0 || axis >= len(lengths) {
+			return math.NaN()
+		}
+		return values[locs[axis]*len(values)/lengths[axis]]
+	}
+}
+
+// weights returns the weight of every location under f, by row-major index.
+// A panic in f is returned as an error.
+func (l *lattice) weights(f Field) (w []float64, err error) {
+
+	var i int
+	defer func() {
+		if r := recover(); r != nil {
+			w, err = nil, fmt.Errorf("weighing %v: %v: %w", l.coords(i), r, ErrInvalidOption)
+		}
+	}()
+
+	w = make([]float64, l.size)
+	var scratch [8]int
+	for i = range w {
+		w[i] = f(l.appendCoords(scratch[:0], i), l.Lengths)
+	}
+
+	return w, l.checkWeights(w)
+}
+
+// checkWeights returns an error unless w holds a finite, non-negative weight
+// for every location.
+func (l *lattice) checkWeights(w []float64) error {
+
+	if len(w) != l.size {
+		return fmt.Errorf("%d weights for %d locations: %w", len(w), l.size, ErrInvalidOption)
+	}
+	for i, x := range w {
+		if !(x >= 0) || math.IsInf(x, 1) {
+			return fmt.Errorf("weight %v at %v: %w", x, l.coords(i), ErrInvalidOption)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package orth
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	type args struct {
+		locs    []int
+		lengths []int
+	}
+	tests := []struct {
+		name  string
+		field Field
+		args  args
+		want  float64
+	}{
+		{
+			name:  "gradient start",
+			field: Gradient(1, 0.2, 0.8),
+			args:  args{locs: []int{3, 0}, lengths: []int{5, 4}},
+			want:  0.2,
+		},
+		{
+			name:  "gradient middle",
+			field: Gradient(1, 0.2, 0.8),
+			args:  args{locs: []int{3, 2}, lengths: []int{5, 4}},
+			want:  0.6,
+		},
+		{
+			name:  "gradient end",
+			field: Gradient(1, 0.2, 0.8),
+			args:  args{locs: []int{0, 3}, lengths: []int{5, 4}},
+			want:  0.8,
+		},
+		{
+			name:  "gradient thin",
+			field: Gradient(0, 0.2, 0.8),
+			args:  args{locs: []int{0, 3}, lengths: []int{1, 4}},
+			want:  0.2,
+		},
+		{
+			name:  "layered first",
+			field: Layered(0, 1, 2, 3),
+			args:  args{locs: []int{1, 0}, lengths: []int{6, 2}},
+			want:  1,
+		},
+		{
+			name:  "layered middle",
+			field: Layered(0, 1, 2, 3),
+			args:  args{locs: []int{3, 0}, lengths: []int{6, 2}},
+			want:  2,
+		},
+		{
+			name:  "layered last",
+			field: Layered(0, 1, 2, 3),
+			args:  args{locs: []int{5, 1}, lengths: []int{6, 2}},
+			want:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.field(tt.args.locs, tt.args.lengths); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Field() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_weights(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    []float64
+		wantErr error
+	}{
+		{
+			name: "uniform",
+		},
+		{
+			name: "field",
+			opts: []Option{WithField(Layered(0, 1, 2))},
+			want: []float64{1, 1, 1, 2, 2, 2},
+		},
+		{
+			name: "weights",
+			opts: []Option{WithWeights([]float64{0, 1, 2, 3, 4, 5})},
+			want: []float64{0, 1, 2, 3, 4, 5},
+		},
+		{
+			name: "last option wins",
+			opts: []Option{WithWeights([]float64{0, 1, 2, 3, 4, 5}), WithField(Gradient(1, 0, 2))},
+			want: []float64{0, 1, 2, 0, 1, 2},
+		},
+		{
+			name:    "too few weights",
+			opts:    []Option{WithWeights([]float64{1, 2})},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "negative weight",
+			opts:    []Option{WithWeights([]float64{0, 1, -2, 3, 4, 5})},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "NaN field",
+			opts:    []Option{WithField(func(locs, lengths []int) float64 { return math.NaN() })},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "infinite field",
+			opts:    []Option{WithField(func(locs, lengths []int) float64 { return math.Inf(1) })},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "gradient along missing axis",
+			opts:    []Option{WithField(Gradient(4, 0, 1))},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "gradient along negative axis",
+			opts:    []Option{WithField(Gradient(-1, 0, 1))},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "layered along missing axis",
+			opts:    []Option{WithField(Layered(2, 1, 2))},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "layered without values",
+			opts:    []Option{WithField(Layered(0))},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "panicking field",
+			opts:    []Option{WithField(func(locs, lengths []int) float64 { return []float64{}[locs[0]] })},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := New([]int{2, 3}, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(o.weights, tt.want) {
+				t.Errorf("New() weights = %v, want %v", o.weights, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrthotope_BuildRandom_weighted(t *testing.T) {
+
+	src := rand.New(rand.NewSource(2))
+	counts := make([]int, 4)
+	for k := 0; k < 4000; k++ {
+		o := newTestOrthotope(t, []int{4}, []Option{WithWeights([]float64{0, 1, 0, 3}), WithSource(src)})
+		locs, err := o.BuildRandom()
+		if err != nil {
+			t.Fatalf("Orthotope.BuildRandom() error = %v", err)
+		}
+		counts[locs[0]]++
+	}
+	if counts[0] != 0 || counts[2] != 0 {
+		t.Fatalf("Orthotope.BuildRandom() built at weight 0: %v", counts)
+	}
+	// 1000 expected at weight 1, with standard deviation about 27.
+	if counts[1] < 850 || counts[1] > 1150 {
+		t.Errorf("Orthotope.BuildRandom() counts = %v, want about 1:3", counts)
+	}
+
+	o := newTestOrthotope(t, []int{4}, []Option{WithWeights([]float64{0, 1, 0, 3}), WithSource(src)})
+	for k := 0; k < 2; k++ {
+		if _, err := o.BuildRandom(); err != nil {
+			t.Fatalf("Orthotope.BuildRandom() error = %v", err)
+		}
+	}
+	if _, err := o.BuildRandom(); !errors.Is(err, ErrInternalState) {
+		t.Errorf("Orthotope.BuildRandom() with only weight 0 left error = %v, want %v", err, ErrInternalState)
+	}
+	if got := bridgeLocations(o); !reflect.DeepEqual(got, [][]int{{1}, {3}}) {
+		t.Errorf("Orthotope.BuildRandom() -> %v, want [[1] [3]]", got)
+	}
+
+	// Demolishing returns a location's weight to the pool.
+	if err := o.Demolish(3); err != nil {
+		t.Fatalf("Orthotope.Demolish() error = %v", err)
+	}
+	checkInvariants(t, o)
+	if locs, err := o.BuildRandom(); err != nil || !reflect.DeepEqual(locs, []int{3}) {
+		t.Errorf("Orthotope.BuildRandom() = %v, %v, want [3]", locs, err)
+	}
+}
+
+func TestOrthotope_weighted_random(t *testing.T) {
+
+	o := newTestOrthotope(t, []int{20, 15}, []Option{
+		WithField(Gradient(0, 0.1, 2)),
+		WithSource(rand.New(rand.NewSource(4))),
+	})
+	for k := 0; k < 600; k++ {
+		var err error
+		if o.nBuilt > 0 && k%3 == 0 {
+			_, err = o.DemolishRandom()
+		} else {
+			_, err = o.BuildRandom()
+		}
+		if err != nil {
+			t.Fatalf("step %d error = %v", k, err)
+		}
+	}
+	checkInvariants(t, o)
+	checkComponents(t, o)
+}
+
+func TestOrthotope_FillBernoulli_field(t *testing.T) {
+
+	o := newTestOrthotope(t, []int{2, 4}, []Option{WithField(Layered(1, 0, 1))})
+	if err := o.FillBernoulli(1, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatalf("Orthotope.FillBernoulli() error = %v", err)
+	}
+	want := [][]int{{0, 2}, {0, 3}, {1, 2}, {1, 3}}
+	if got := bridgeLocations(o); !reflect.DeepEqual(got, want) {
+		t.Errorf("Orthotope.FillBernoulli() -> %v, want %v", got, want)
+	}
+	checkInvariants(t, o)
+	checkComponents(t, o)
+}
@@ -0,0 +1,283 @@
+package orth
+
+// forest is a disjoint-set forest over the integers [0, n) using union by size
+// and path halving, so find and union run in amortized near-constant time.
+// Each root carries the union of the flags of its members. Only roots ever
+// read their flags, and most carry none, so the flags that are not 0 are kept
+// in a map by root rather than taking a word for every node.
+//
+// When created with k > 0, the forest also tracks where each node lies relative
+// to its parent along k periodic dimensions, counted without wrapping. Two
+// nodes of one set joined again at a different relative position close a loop
+// that winds around those dimensions.
+//
+// Nodes are stored as int32 to halve the footprint of large orthotopes.
+type forest struct {
+	parent []int32
+	size   []int32
+
+	// flagged marks the roots in tags, those whose flags are not 0.
+	flagged bitset
+	tags    map[int32]uint64
+
+	// If counts is not nil, the low counted bits of flags are counted rather
+	// than or'ed: counts holds, for each root with any of them, how many
+	// members were reset with each bit, so members can be dropped again.
+	counted uint
+	counts  map[int32][]int32
+
+	// k is the number of tracked dimensions and shift holds, k per node, the
+	// position of each node's parent minus its own.
+	k     int
+	shift []int32
+
+	// Scratch space for link.
+	da, db []int
+}
+
+func newForest(n, k int) *forest {
+
+	f := &forest{
+		parent:  make([]int32, n),
+		size:    make([]int32, n),
+		flagged: newBitset(n),
+		tags:    map[int32]uint64{},
+		k:       k,
+		da:      make([]int, k),
+		db:      make([]int, k),
+	}
+	if k > 0 {
+		f.shift = make([]int32, n*k)
+	}
+	for i := range f.parent {
+		f.parent[i] = int32(i)
+		f.size[i] = 1
+	}
+
+	return f
+}
+
+// find returns the root of the set containing x.
+func (f *forest) find(x int) int {
+	return f.locate(x, nil)
+}
+
+// locate returns the root of the set containing x and, if offset is not nil,
+// sets its k entries to the position of the root minus that of x.
+func (f *forest) locate(x int, offset []int) int {
+
+	for c := range offset {
+		offset[c] = 0
+	}
+
+	for int(f.parent[x]) != x {
+		// Path halving: point x at its grandparent while walking up.
+		p := int(f.parent[x])
+		for c := 0; c < f.k; c++ {
+			f.shift[x*f.k+c] += f.shift[p*f.k+c]
+		}
+		f.parent[x] = f.parent[p]
+
+		for c := range offset {
+			offset[c] += int(f.shift[x*f.k+c])
+		}
+		x = int(f.parent[x])
+	}
+
+	return x
+}
+
+// union merges the sets containing a and b and returns the new root.
+func (f *forest) union(a, b int) int {
+
+	root, _ := f.link(a, b, nil)
+	return root
+}
+
+// link merges the sets containing a and b, where b lies at the k entries of
+// step from a, and returns the new root. If a and b were already in one set,
+// it also returns a bit for each tracked dimension the loop through step
+// winds around.
+func (f *forest) link(a, b int, step []int) (int, uint64) {
+
+	ra := f.locate(a, f.da)
+	rb := f.locate(b, f.db)
+
+	// The position of rb minus ra, were b where step puts it from a.
+	for c := 0; c < f.k; c++ {
+		var s int
+		if step != nil {
+			s = step[c]
+		}
+		f.db[c] = s + f.db[c] - f.da[c]
+	}
+
+	if ra == rb {
+		var winds uint64
+		for c := 0; c < f.k; c++ {
+			if f.db[c] != 0 {
+				winds |= 1 << uint(c)
+			}
+		}
+		return ra, winds
+	}
+
+	// Attach the smaller tree under the larger one.
+	if f.size[ra] < f.size[rb] {
+		ra, rb = rb, ra
+		for c := 0; c < f.k; c++ {
+			f.db[c] = -f.db[c]
+		}
+	}
+	f.parent[rb] = int32(ra)
+	for c := 0; c < f.k; c++ {
+		f.shift[rb*f.k+c] = int32(-f.db[c])
+	}
+	f.size[ra] += f.size[rb]
+	if cb, ok := f.counts[int32(rb)]; ok {
+		if ca, ok := f.counts[int32(ra)]; ok {
+			for b := range ca {
+				ca[b] += cb[b]
+			}
+		} else {
+			f.counts[int32(ra)] = cb
+		}
+		delete(f.counts, int32(rb))
+	}
+	if f.flagged.get(rb) {
+		f.mark(ra, f.tags[int32(rb)])
+		f.flagged.clear(rb)
+		delete(f.tags, int32(rb))
+	}
+
+	return ra, 0
+}
+
+// flags returns the flags of the root r.
+func (f *forest) flags(r int) uint64 {
+
+	if !f.flagged.get(r) {
+		return 0
+	}
+
+	return f.tags[int32(r)]
+}
+
+// mark adds flags to those of the root r.
+func (f *forest) mark(r int, flags uint64) {
+
+	if flags == 0 {
+		return
+	}
+	f.flagged.set(r)
+	f.tags[int32(r)] |= flags
+}
+
+// connected returns whether a and b are in the same set.
+func (f *forest) connected(a, b int) bool {
+	return f.find(a) == f.find(b)
+}
+
+// reset detaches x into a set of its own carrying flags. Any node whose parent
+// is x must be reset as well.
+func (f *forest) reset(x int, flags uint64) {
+
+	f.parent[x] = int32(x)
+	f.size[x] = 1
+	for c := 0; c < f.k; c++ {
+		f.shift[x*f.k+c] = 0
+	}
+	if f.flagged.get(x) {
+		f.flagged.clear(x)
+		delete(f.tags, int32(x))
+	}
+	f.mark(x, flags)
+
+	if f.counts == nil {
+		return
+	}
+	delete(f.counts, int32(x))
+	if flags&(1<<f.counted-1) == 0 {
+		return
+	}
+	c := make([]int32, f.counted)
+	for b := range c {
+		if flags&(1<<uint(b)) != 0 {
+			c[b] = 1
+		}
+	}
+	f.counts[int32(x)] = c
+}
+
+// count makes the forest count the low bits of flags for each member, so that
+// drop can take them away again. It must be called before any reset.
+func (f *forest) count(bits uint) {
+
+	f.counted = bits
+	f.counts = map[int32][]int32{}
+}
+
+// drop takes a member reset with flags out of the count of the root r, which
+// must be counting them. The member itself is left as it is.
+func (f *forest) drop(r int, flags uint64) {
+
+	f.size[r]--
+	c, ok := f.counts[int32(r)]
+	if !ok || flags&(1<<f.counted-1) == 0 {
+		return
+	}
+
+	var left bool
+	for b := range c {
+		if flags&(1<<uint(b)) != 0 {
+			c[b]--
+			if c[b] == 0 {
+				f.tags[int32(r)] &^= 1 << uint(b)
+			}
+		}
+		left = left || c[b] > 0
+	}
+	if !left {
+		delete(f.counts, int32(r))
+	}
+	if f.tags[int32(r)] == 0 {
+		f.flagged.clear(r)
+		delete(f.tags, int32(r))
+	}
+}
+
+// add appends a node in a set of its own carrying flags and returns it.
+func (f *forest) add(flags uint64) int {
+
+	x := len(f.parent)
+	if x == cap(f.parent) {
+		f.grow(x/4 + 16)
+	}
+	f.parent = append(f.parent, int32(x))
+	f.size = append(f.size, 1)
+	for c := 0; c < f.k; c++ {
+		f.shift = append(f.shift, 0)
+	}
+	if x/64 == len(f.flagged) {
+		f.flagged = append(f.flagged, 0)
+	}
+	f.reset(x, flags)
+
+	return x
+}
+
+// grow makes room for n more nodes without the doubling of append.
+func (f *forest) grow(n int) {
+
+	parent := make([]int32, len(f.parent), cap(f.parent)+n)
+	copy(parent, f.parent)
+	f.parent = parent
+	size := make([]int32, len(f.size), cap(f.parent))
+	copy(size, f.size)
+	f.size = size
+	if f.k > 0 {
+		shift := make([]int32, len(f.shift), cap(f.parent)*f.k)
+		copy(shift, f.shift)
+		f.shift = shift
+	}
+}
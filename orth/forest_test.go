@@ -0,0 +1,179 @@
+package orth
+
+import "testing"
+
+func Test_forest(t *testing.T) {
+	type union struct {
+		a, b int
+	}
+	tests := []struct {
+		name         string
+		n            int
+		unions       []union
+		connected    [][2]int
+		disconnected [][2]int
+	}{
+		{
+			name:         "singletons",
+			n:            3,
+			disconnected: [][2]int{{0, 1}, {1, 2}, {0, 2}},
+		},
+		{
+			name:         "chain",
+			n:            5,
+			unions:       []union{{0, 1}, {1, 2}, {3, 4}},
+			connected:    [][2]int{{0, 2}, {2, 0}, {3, 4}},
+			disconnected: [][2]int{{0, 3}, {2, 4}},
+		},
+		{
+			name:      "merge chains",
+			n:         6,
+			unions:    []union{{0, 1}, {2, 3}, {4, 5}, {1, 3}, {5, 0}},
+			connected: [][2]int{{0, 5}, {2, 4}, {1, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newForest(tt.n, 0)
+			for _, u := range tt.unions {
+				f.union(u.a, u.b)
+			}
+			for _, c := range tt.connected {
+				if !f.connected(c[0], c[1]) {
+					t.Errorf("forest.connected(%d, %d) = false, want true", c[0], c[1])
+				}
+			}
+			for _, c := range tt.disconnected {
+				if f.connected(c[0], c[1]) {
+					t.Errorf("forest.connected(%d, %d) = true, want false", c[0], c[1])
+				}
+			}
+
+			var total int
+			for i := 0; i < tt.n; i++ {
+				if f.find(i) == i {
+					total += int(f.size[i])
+				}
+			}
+			if total != tt.n {
+				t.Errorf("sum of root sizes = %d, want %d", total, tt.n)
+			}
+		})
+	}
+}
+
+func Test_forest_link(t *testing.T) {
+	type link struct {
+		a, b int
+		step []int
+	}
+	tests := []struct {
+		name      string
+		n, k      int
+		links     []link
+		wantWinds uint64
+	}{
+		{
+			name:  "open chain",
+			n:     3,
+			k:     1,
+			links: []link{{0, 1, []int{1}}, {1, 2, []int{1}}},
+		},
+		{
+			name:  "closed loop without winding",
+			n:     4,
+			k:     1,
+			links: []link{{0, 1, []int{1}}, {1, 2, []int{0}}, {2, 3, []int{-1}}, {3, 0, []int{0}}},
+		},
+		{
+			name:      "ring of 3",
+			n:         3,
+			k:         1,
+			links:     []link{{0, 1, []int{1}}, {1, 2, []int{1}}, {2, 0, []int{1}}},
+			wantWinds: 1,
+		},
+		{
+			name:      "winds second dimension",
+			n:         4,
+			k:         2,
+			links:     []link{{0, 1, []int{0, 1}}, {2, 3, []int{0, 1}}, {1, 2, []int{1, 0}}, {3, 0, []int{-1, 1}}},
+			wantWinds: 2,
+		},
+		{
+			name:      "self loop",
+			n:         1,
+			k:         1,
+			links:     []link{{0, 0, []int{1}}},
+			wantWinds: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newForest(tt.n, tt.k)
+			var winds uint64
+			for _, l := range tt.links {
+				_, w := f.link(l.a, l.b, l.step)
+				winds |= w
+			}
+			if winds != tt.wantWinds {
+				t.Errorf("forest.link() winds = %b, want %b", winds, tt.wantWinds)
+			}
+		})
+	}
+}
+
+func Test_forest_flags(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		reset map[int]uint64
+		marks map[int]uint64
+		links [][2]int
+		want  map[int]uint64
+	}{
+		{
+			name: "none",
+			n:    3,
+			want: map[int]uint64{0: 0, 1: 0, 2: 0},
+		},
+		{
+			name:  "merged at root",
+			n:     4,
+			reset: map[int]uint64{0: 1, 2: 4},
+			marks: map[int]uint64{3: 8},
+			links: [][2]int{{0, 1}, {2, 3}, {1, 3}},
+			want:  map[int]uint64{0: 13, 1: 13, 2: 13, 3: 13},
+		},
+		{
+			name:  "reset clears",
+			n:     2,
+			marks: map[int]uint64{0: 2},
+			reset: map[int]uint64{0: 0},
+			want:  map[int]uint64{0: 0, 1: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newForest(tt.n, 0)
+			for x, flags := range tt.marks {
+				f.mark(x, flags)
+			}
+			for x, flags := range tt.reset {
+				f.reset(x, flags)
+			}
+			for _, l := range tt.links {
+				f.union(l[0], l[1])
+			}
+			for x, want := range tt.want {
+				if got := f.flags(f.find(x)); got != want {
+					t.Errorf("forest.flags(find(%d)) = %b, want %b", x, got, want)
+				}
+			}
+			for r := range f.tags {
+				if f.find(int(r)) != int(r) {
+					t.Errorf("non-root %d has flags", r)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package orth
+
+import (
+	"fmt"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// GIFOptions configures an Animation. Its PNGOptions choose the cell size,
+// colors and cross-section of every frame.
+type GIFOptions struct {
+	PNGOptions
+	// Every is the number of builds between frames. The default is 1.
+	Every int
+	// Delay is how long each frame shows, and Hold how long the final frame
+	// does. The defaults are 100ms and 2s. GIF rounds both to 10ms.
+	Delay time.Duration
+	Hold  time.Duration
+	// Path colors the spanning path on the final frame. The default is gold.
+	Path color.Color
+}
+
+// Animation records an Orthotope being built as the frames of an animated GIF.
+type Animation struct {
+	opts    GIFOptions
+	size    int
+	palette color.Palette
+	builds  int
+	gif     gif.GIF
+}
+
+// NewAnimation returns an Animation with no frames.
+func NewAnimation(opts GIFOptions) (*Animation, error) {
+
+	size, err := opts.cellSize()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Every < 0 {
+		return nil, fmt.Errorf("every %d builds: %w", opts.Every, ErrInvalidOption)
+	}
+	if opts.Every == 0 {
+		opts.Every = 1
+	}
+	if opts.Delay < 0 || opts.Hold < 0 {
+		return nil, fmt.Errorf("delay %v, hold %v: %w", opts.Delay, opts.Hold, ErrInvalidOption)
+	}
+	if opts.Delay == 0 {
+		opts.Delay = 100 * time.Millisecond
+	}
+	if opts.Hold == 0 {
+		opts.Hold = 2 * time.Second
+	}
+
+	palette := append(opts.palette(), orColor(opts.Path, color.RGBA{R: 0xf1, G: 0xc4, B: 0x0f, A: 0xff}))
+
+	return &Animation{opts: opts, size: size, palette: palette}, nil
+}
+
+// Capture counts one build on o, adding a frame of o every opts.Every builds.
+func (a *Animation) Capture(o *Orthotope) error {
+
+	a.builds++
+	if a.builds%a.opts.Every != 0 {
+		return nil
+	}
+
+	return a.frame(o, false, a.opts.Delay)
+}
+
+// WriteGIF adds a final frame of o with its spanning path, if any, highlighted
+// and writes the animation to w, looping forever.
+func (a *Animation) WriteGIF(w io.Writer, o *Orthotope) error {
+
+	if err := a.frame(o, true, a.opts.Hold); err != nil {
+		return err
+	}
+
+	return gif.EncodeAll(w, &a.gif)
+}
+
+// Frames returns the number of frames recorded so far.
+func (a *Animation) Frames() int {
+	return len(a.gif.Image)
+}
+
+// frame adds a frame of o shown for delay.
+func (a *Animation) frame(o *Orthotope, path bool, delay time.Duration) error {
+
+	v, err := o.newView(a.opts.Slice)
+	if err != nil {
+		return err
+	}
+
+	a.gif.Image = append(a.gif.Image, v.raster(a.size, a.palette, path))
+	a.gif.Delay = append(a.gif.Delay, int(delay/(10*time.Millisecond)))
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package orth
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+func TestAnimation(t *testing.T) {
+
+	empty := color.RGBA{A: 0xff}
+	bridge := color.RGBA{G: 0xff, A: 0xff}
+	spanning := color.RGBA{R: 0xff, A: 0xff}
+	path := color.RGBA{B: 0xff, A: 0xff}
+	colors := PNGOptions{Empty: empty, Bridge: bridge, Spanning: spanning}
+
+	type args struct {
+		lengths []int
+		builds  [][]int
+	}
+	tests := []struct {
+		name       string
+		opts       GIFOptions
+		args       args
+		wantDelays []int
+		// wantLast is the final frame, . empty, b bridge, s spanning and p
+		// path, by pixel row.
+		wantLast []string
+		wantErr  error
+	}{
+		{
+			name: "every build",
+			opts: GIFOptions{PNGOptions: colors, Path: path},
+			args: args{
+				lengths: []int{3, 2},
+				builds:  [][]int{{0, 1}, {2, 0}, {1, 1}, {2, 1}},
+			},
+			wantDelays: []int{10, 10, 10, 10, 200},
+			wantLast:   []string{"ppp", "..s"},
+		},
+		{
+			name: "every other build",
+			opts: GIFOptions{PNGOptions: colors, Path: path, Every: 2, Delay: 50 * time.Millisecond, Hold: time.Second},
+			args: args{
+				lengths: []int{3, 2},
+				builds:  [][]int{{0, 1}, {2, 0}, {1, 1}},
+			},
+			wantDelays: []int{5, 100},
+			wantLast:   []string{"bb.", "..b"},
+		},
+		{
+			name: "cell size",
+			opts: GIFOptions{PNGOptions: PNGOptions{CellSize: 2, Empty: empty, Bridge: bridge, Spanning: spanning}, Path: path},
+			args: args{
+				lengths: []int{2, 1},
+				builds:  [][]int{{0, 0}, {1, 0}},
+			},
+			wantDelays: []int{10, 10, 200},
+			wantLast:   []string{"pppp", "pppp"},
+		},
+		{
+			name:    "negative every",
+			opts:    GIFOptions{Every: -1},
+			args:    args{lengths: []int{2, 2}},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "negative delay",
+			opts:    GIFOptions{Delay: -time.Second},
+			args:    args{lengths: []int{2, 2}},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAnimation(tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAnimation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			o := newTestOrthotope(t, tt.args.lengths, nil)
+			for _, locs := range tt.args.builds {
+				if err := o.Build(locs...); err != nil {
+					t.Fatalf("Orthotope.Build(%v) error = %v", locs, err)
+				}
+				if err := a.Capture(o); err != nil {
+					t.Fatalf("Animation.Capture() error = %v", err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := a.WriteGIF(&buf, o); err != nil {
+				t.Fatalf("Animation.WriteGIF() error = %v", err)
+			}
+			if a.Frames() != len(tt.wantDelays) {
+				t.Errorf("Animation.Frames() = %d, want %d", a.Frames(), len(tt.wantDelays))
+			}
+
+			g, err := gif.DecodeAll(&buf)
+			if err != nil {
+				t.Fatalf("gif.DecodeAll() error = %v", err)
+			}
+			if len(g.Delay) != len(tt.wantDelays) {
+				t.Fatalf("Animation.WriteGIF() delays = %v, want %v", g.Delay, tt.wantDelays)
+			}
+			for k := range g.Delay {
+				if g.Delay[k] != tt.wantDelays[k] {
+					t.Fatalf("Animation.WriteGIF() delays = %v, want %v", g.Delay, tt.wantDelays)
+				}
+			}
+
+			names := map[color.RGBA]byte{empty: '.', bridge: 'b', spanning: 's', path: 'p'}
+			last := g.Image[len(g.Image)-1]
+			for y, want := range tt.wantLast {
+				var row []byte
+				for x := 0; x < last.Bounds().Dx(); x++ {
+					row = append(row, names[color.RGBAModel.Convert(last.At(x, y)).(color.RGBA)])
+				}
+				if string(row) != want {
+					t.Errorf("Animation.WriteGIF() last frame row %d = %q, want %q", y, row, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package orth
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// InvasionOption configures an invasion run by Invade.
+type InvasionOption func(*invasionConfig)
+
+type invasionConfig struct {
+	trapping bool
+}
+
+// WithTrapping makes unoccupied regions that the invader has cut off from the
+// n_1-1 face of the 1st dimension, the outlet, trapped: they can no longer be
+// invaded, as an incompressible defending fluid would not let them.
+func WithTrapping() InvasionOption {
+	return func(c *invasionConfig) {
+		c.trapping = true
+	}
+}
+
+// Invade grows bridges by invasion percolation on an empty Orthotope. Every
+// location gets a random resistance drawn from the Orthotope's Source, and the
+// invader enters through the 0 face of the 1st dimension: the unoccupied
+// location of least resistance on that face or bordering an invaded one is
+// occupied next, until BridgeComplete. It returns the locations in the order invaded. If the
+// invasion runs out of locations first, it returns them with an error
+// wrapping ErrNoPath.
+func (o *Orthotope) Invade(opts ...InvasionOption) ([][]int, error) {
+
+	var c invasionConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if o.nBuilt > 0 {
+		return nil, fmt.Errorf("invasion needs an empty orthotope, %d bridges: %w", o.nBuilt, ErrOccupied)
+	}
+	if len(o.Lengths) == 0 || o.periodic[0] {
+		return nil, fmt.Errorf("invasion needs an %v 1st dimension to enter through: %w", Open, ErrInvalidOption)
+	}
+
+	resistance := make([]float64, len(o.cells))
+	for i := range resistance {
+		resistance[i] = uniform(o.source)
+	}
+
+	order, complete := o.invade(resistance)
+	if c.trapping {
+		trapped := o.trapped(order)
+		o.clear()
+		kept := order[:0]
+		for _, i := range order {
+			if trapped.get(i) {
+				continue
+			}
+			o.occupy(i)
+			kept = append(kept, i)
+		}
+		order = kept
+	}
+
+	locs := make([][]int, len(order))
+	for k, i := range order {
+		locs[k] = o.coords(i)
+	}
+	if !complete {
+		return locs, fmt.Errorf("invasion of %v ran out of locations: %w", o.Lengths, ErrNoPath)
+	}
+
+	return locs, nil
+}
+
+// invade occupies locations by invasion percolation without trapping until
+// BridgeComplete, returning their indices in order and whether it completed.
+func (o *Orthotope) invade(resistance []float64) ([]int, bool) {
+
+	queued := newBitset(len(o.cells))
+	perimeter := &frontier{resistance: resistance}
+	for i := range o.cells {
+		if i/o.strides[0] == 0 {
+			queued.set(i)
+			perimeter.indices = append(perimeter.indices, i)
+		}
+	}
+	heap.Init(perimeter)
+
+	var order []int
+	var buf []int
+	for {
+		if complete, _ := o.BridgeComplete(); complete {
+			return order, true
+		}
+		if perimeter.Len() == 0 {
+			return order, false
+		}
+
+		i := heap.Pop(perimeter).(int)
+		o.occupy(i)
+		order = append(order, i)
+
+		buf = o.neighbors(i, buf[:0])
+		for _, n := range buf {
+			if !queued.get(n) {
+				queued.set(n)
+				heap.Push(perimeter, n)
+			}
+		}
+	}
+}
+
+// trapped returns which of the locations invaded in order were trapped when
+// invaded: cut off from the outlet through unoccupied locations. It adds them
+// back to the unoccupied ones in reverse order, so each is checked against
+// exactly the locations unoccupied when it was invaded.
+func (o *Orthotope) trapped(order []int) bitset {
+
+	outlet := len(o.cells)
+	defender := newForest(len(o.cells)+1, 0)
+	free := newBitset(len(o.cells))
+	var buf []int
+	add := func(i int) {
+		free.set(i)
+		if i/o.strides[0] == o.Lengths[0]-1 {
+			defender.union(i, outlet)
+		}
+		buf = o.neighbors(i, buf[:0])
+		for _, n := range buf {
+			if free.get(n) {
+				defender.union(i, n)
+			}
+		}
+	}
+
+	for i := range o.cells {
+		if !o.built.get(i) {
+			add(i)
+		}
+	}
+
+	trapped := newBitset(len(o.cells))
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		add(i)
+		if !defender.connected(i, outlet) {
+			trapped.set(i)
+		}
+	}
+
+	return trapped
+}
+
+// frontier is a min-heap of location indices by resistance, ties going to the
+// lower index.
+type frontier struct {
+	indices    []int
+	resistance []float64
+}
+
+func (f *frontier) Len() int { return len(f.indices) }
+
+func (f *frontier) Less(a, b int) bool {
+	ra, rb := f.resistance[f.indices[a]], f.resistance[f.indices[b]]
+	if ra != rb {
+		return ra < rb
+	}
+	return f.indices[a] < f.indices[b]
+}
+
+func (f *frontier) Swap(a, b int) { f.indices[a], f.indices[b] = f.indices[b], f.indices[a] }
+
+func (f *frontier) Push(x interface{}) { f.indices = append(f.indices, x.(int)) }
+
+func (f *frontier) Pop() interface{} {
+	last := f.indices[len(f.indices)-1]
+	f.indices = f.indices[:len(f.indices)-1]
+	return last
+}
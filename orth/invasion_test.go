@@ -0,0 +1,177 @@
+package orth
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// resistances is a Source making uniform return each of its values in turn,
+// to the nearest multiple of 2^-26.
+type resistances []float64
+
+func (r *resistances) Intn(n int) int {
+	if n == 1<<27 {
+		return 0
+	}
+	v := (*r)[0]
+	*r = (*r)[1:]
+	return int(v * (1 << 26))
+}
+
+func TestOrthotope_Invade(t *testing.T) {
+	type fields struct {
+		Lengths    []int
+		opts       []Option
+		resistance resistances
+		built      [][]int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		invOpts []InvasionOption
+		want    [][]int
+		wantErr error
+	}{
+		{
+			name: "1D",
+			fields: fields{
+				Lengths:    []int{4},
+				resistance: resistances{0.9, 0.1, 0.8, 0.2},
+			},
+			want: [][]int{{0}, {1}, {2}, {3}},
+		},
+		{
+			name: "least resistance first",
+			fields: fields{
+				Lengths: []int{3, 3},
+				resistance: resistances{
+					0.5, 0.1, 0.9,
+					0.8, 0.2, 0.7,
+					0.6, 0.95, 0.3,
+				},
+			},
+			want: [][]int{{0, 1}, {1, 1}, {0, 0}, {1, 2}, {2, 2}},
+		},
+		{
+			name: "without trapping",
+			fields: fields{
+				Lengths: []int{4, 3},
+				resistance: resistances{
+					0.1, 0.1, 0.1,
+					0.1, 0.9, 0.1,
+					0.1, 0.1, 0.1,
+					0.95, 0.95, 0.95,
+				},
+			},
+			want: [][]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 1}, {2, 2}, {1, 1}, {3, 0}},
+		},
+		{
+			name: "with trapping",
+			fields: fields{
+				Lengths: []int{4, 3},
+				resistance: resistances{
+					0.1, 0.1, 0.1,
+					0.1, 0.9, 0.1,
+					0.1, 0.1, 0.1,
+					0.95, 0.95, 0.95,
+				},
+			},
+			invOpts: []InvasionOption{WithTrapping()},
+			want:    [][]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 1}, {2, 2}, {3, 0}},
+		},
+		{
+			name: "not empty",
+			fields: fields{
+				Lengths: []int{3, 3},
+				built:   [][]int{{1, 1}},
+			},
+			wantErr: ErrOccupied,
+		},
+		{
+			name: "periodic inlet",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithBoundaries(Periodic, Open), WithSpanningRule(SpanAxis(1))},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "never complete",
+			fields: fields{
+				Lengths:    []int{2, 2},
+				opts:       []Option{WithNeighborhood(Stencil([]int{0, 1}))},
+				resistance: resistances{0.5, 0.4, 0.1, 0.2},
+			},
+			want:    [][]int{{0, 1}, {0, 0}},
+			wantErr: ErrNoPath,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.fields.opts
+			if tt.fields.resistance != nil {
+				opts = append(opts, WithSource(&tt.fields.resistance))
+			}
+			o := newTestOrthotope(t, tt.fields.Lengths, opts, tt.fields.built...)
+			got, err := o.Invade(tt.invOpts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.Invade() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.Invade() = %v, want %v", got, tt.want)
+			}
+			checkInvariants(t, o)
+			checkComponents(t, o)
+		})
+	}
+}
+
+func TestOrthotope_Invade_random(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+		invOpts []InvasionOption
+	}{
+		{name: "2D", lengths: []int{30, 20}},
+		{name: "2D trapping", lengths: []int{30, 20}, invOpts: []InvasionOption{WithTrapping()}},
+		{name: "3D periodic trapping", lengths: []int{8, 6, 6}, opts: []Option{WithBoundaries(Open, Periodic, Periodic)}, invOpts: []InvasionOption{WithTrapping()}},
+		{name: "2D Moore trapping", lengths: []int{30, 20}, opts: []Option{WithNeighborhood(Moore)}, invOpts: []InvasionOption{WithTrapping()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.lengths, append(tt.opts, WithSource(rand.New(rand.NewSource(6)))))
+			got, err := o.Invade(tt.invOpts...)
+			if err != nil {
+				t.Fatalf("Orthotope.Invade() error = %v", err)
+			}
+			checkInvariants(t, o)
+			checkComponents(t, o)
+
+			if complete, _ := o.BridgeComplete(); !complete {
+				t.Errorf("Orthotope.Invade() left BridgeComplete() false")
+			}
+			if len(got) != o.nBuilt {
+				t.Errorf("Orthotope.Invade() returned %d locations, built %d", len(got), o.nBuilt)
+			}
+
+			// Every invaded location is connected to the 0 face it grew from.
+			if got[0][0] != 0 {
+				t.Errorf("Orthotope.Invade() started at %v", got[0])
+			}
+			inlet := map[int]bool{}
+			for _, l := range got {
+				if l[0] == 0 {
+					inlet[o.root(o.index(l...))] = true
+				}
+			}
+			for _, l := range got {
+				if !inlet[o.root(o.index(l...))] {
+					t.Fatalf("Orthotope.Invade() location %v not connected to the 0 face", l)
+				}
+			}
+		})
+	}
+}
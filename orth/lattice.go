@@ -0,0 +1,278 @@
+package orth
+
+import "fmt"
+
+// lattice is the geometry shared by site and bond orthotopes: N = len(Lengths)
+// dimensions with side lengths n_1 = Lengths[0], ..., n_N = Lengths[N-1],
+// each Open or Periodic, and the row-major index of every location, where the
+// last dimension varies fastest.
+type lattice struct {
+	Lengths  []int
+	periodic []bool
+	wraps    bool
+	strides  []int
+	// size is the number of locations.
+	size int
+}
+
+func newLattice(lengths []int, boundaries []Boundary) (lattice, error) {
+
+	if len(lengths) > maxDims {
+		return lattice{}, fmt.Errorf("%d dimensions exceed %d: %w", len(lengths), maxDims, ErrTooLarge)
+	}
+
+	periodic := make([]bool, len(lengths))
+	wraps := false
+	if boundaries != nil {
+		if len(boundaries) != len(lengths) {
+			return lattice{}, fmt.Errorf("%d boundaries for %d dimensions: %w", len(boundaries), len(lengths), ErrInvalidOption)
+		}
+		for d, b := range boundaries {
+			switch b {
+			case Open:
+			case Periodic:
+				periodic[d] = true
+				wraps = true
+			default:
+				return lattice{}, fmt.Errorf("boundary %d of dimension %d: %w", b, d, ErrInvalidOption)
+			}
+		}
+	}
+
+	// Row-major strides: the last dimension varies fastest.
+	strides := make([]int, len(lengths))
+	size := 1
+	for i := len(lengths) - 1; i >= 0; i-- {
+		if lengths[i] < 0 {
+			return lattice{}, fmt.Errorf("negative length %d in %v: %w", lengths[i], lengths, ErrOutOfBounds)
+		}
+		strides[i] = size
+		if lengths[i] > 0 && size > maxCells/lengths[i] {
+			return lattice{}, fmt.Errorf("lengths %v exceed %d locations: %w", lengths, maxCells, ErrTooLarge)
+		}
+		size *= lengths[i]
+	}
+	if len(lengths) == 0 {
+		size = 0
+	}
+
+	l := lattice{
+		Lengths:  lengths,
+		periodic: periodic,
+		wraps:    wraps,
+		strides:  strides,
+		size:     size,
+	}
+	return l, nil
+}
+
+// periodicSteps returns the movement of each offset along the periodic
+// dimensions only, for tracking wrapping in a forest.
+func (l *lattice) periodicSteps(offsets [][]int) [][]int {
+
+	steps := make([][]int, len(offsets))
+	for k, offset := range offsets {
+		for d, p := range l.periodic {
+			if p {
+				steps[k] = append(steps[k], offset[d])
+			}
+		}
+	}
+
+	return steps
+}
+
+// countPeriodic returns the number of periodic dimensions.
+func (l *lattice) countPeriodic() int {
+
+	var k int
+	for _, p := range l.periodic {
+		if p {
+			k++
+		}
+	}
+
+	return k
+}
+
+func (l *lattice) inBound(locs ...int) bool {
+
+	if len(locs) != len(l.Lengths) {
+		return false
+	}
+
+	for i, loc := range locs {
+		length := l.Lengths[i]
+		if loc < 0 || loc >= length {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasCell reports whether locs is in bound and has a cell behind it. A 0-D
+// lattice accepts the empty location but stores no cells.
+func (l *lattice) hasCell(locs ...int) bool {
+	return l.size > 0 && l.inBound(locs...)
+}
+
+// index returns the row-major position of the in bound location locs.
+// Example: lengths [3,4,5], locs [1,2,3] -> 1*20 + 2*5 + 3 = 33
+func (l *lattice) index(locs ...int) int {
+
+	var i int
+	for d, loc := range locs {
+		i += loc * l.strides[d]
+	}
+
+	return i
+}
+
+// coords returns the location of the row-major position i.
+// Example: lengths [3,4,5], i 33 -> [1,2,3]
+func (l *lattice) coords(i int) []int {
+	return l.appendCoords(make([]int, 0, len(l.strides)), i)
+}
+
+// appendCoords appends the location of the row-major position i to locs.
+func (l *lattice) appendCoords(locs []int, i int) []int {
+
+	for _, stride := range l.strides {
+		locs = append(locs, i/stride)
+		i %= stride
+	}
+
+	return locs
+}
+
+// step returns the index offset from index i at location locs, wrapping
+// around periodic dimensions, and whether it is in bounds.
+func (l *lattice) step(i int, locs []int, offset []int) (int, bool) {
+
+	j := i
+	for d, delta := range offset {
+		loc := locs[d] + delta
+		if loc < 0 || loc >= l.Lengths[d] {
+			if !l.periodic[d] {
+				return 0, false
+			}
+			loc = ((loc % l.Lengths[d]) + l.Lengths[d]) % l.Lengths[d]
+		}
+		j += (loc - locs[d]) * l.strides[d]
+	}
+
+	return j, true
+}
+
+// Flags of a set of locations in N dimensions: bit d for touching the 0 face
+// of Open dimension d, bit N+d for its n-1 face and bit 2N+d for wrapping
+// around Periodic dimension d.
+
+// faces returns the flags of a set holding only the bridge at index i.
+func (l *lattice) faces(i int) uint64 {
+
+	var flags uint64
+	n := uint(len(l.Lengths))
+	for d, stride := range l.strides {
+		loc := i / stride
+		i %= stride
+		if l.periodic[d] {
+			continue
+		}
+		if loc == 0 {
+			flags |= 1 << uint(d)
+		}
+		if loc == l.Lengths[d]-1 {
+			flags |= 1 << (n + uint(d))
+		}
+	}
+
+	return flags
+}
+
+// windFlags returns the flags of wrapping around the periodic dimensions with
+// bits set in winds, numbered among the periodic dimensions only.
+func (l *lattice) windFlags(winds uint64) uint64 {
+
+	var flags uint64
+	n := uint(len(l.Lengths))
+	c := uint(0)
+	for d, p := range l.periodic {
+		if !p {
+			continue
+		}
+		if winds&(1<<c) != 0 {
+			flags |= 1 << (2*n + uint(d))
+		}
+		c++
+	}
+
+	return flags
+}
+
+// spans returns a bit for each dimension spanned by a set with flags.
+func (l *lattice) spans(flags uint64) uint64 {
+
+	n := uint(len(l.Lengths))
+	dims := uint64(1)<<n - 1
+	low := flags & dims
+	high := (flags >> n) & dims
+	wrapped := (flags >> (2 * n)) & dims
+
+	return low&high | wrapped
+}
+
+// tally counts the sets of connected locations spanning each dimension, and
+// those spanning every dimension at once.
+type tally struct {
+	spanned []int
+	all     int
+}
+
+func newTally(dims int) tally {
+	return tally{spanned: make([]int, dims)}
+}
+
+// add adds delta to the counts for a set spanning the dimensions in mask.
+func (t *tally) add(mask uint64, delta int) {
+
+	if mask == 0 {
+		return
+	}
+
+	for d := range t.spanned {
+		if mask&(1<<uint(d)) != 0 {
+			t.spanned[d] += delta
+		}
+	}
+	if mask == 1<<uint(len(t.spanned))-1 {
+		t.all += delta
+	}
+}
+
+// axes returns a bit for each dimension spanned by some set.
+func (t *tally) axes() uint64 {
+
+	var mask uint64
+	for d, n := range t.spanned {
+		if n > 0 {
+			mask |= 1 << uint(d)
+		}
+	}
+
+	return mask
+}
+
+// list returns, in increasing order, the dimensions spanned by some set.
+func (t *tally) list() []int {
+
+	axes := []int{}
+	for d, n := range t.spanned {
+		if n > 0 {
+			axes = append(axes, d)
+		}
+	}
+
+	return axes
+}
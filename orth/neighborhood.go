@@ -0,0 +1,136 @@
+package orth
+
+import "fmt"
+
+// Neighborhood defines which locations count as neighbors, and so which
+// bridges are connected to each other.
+type Neighborhood struct {
+	name string
+	// reach is the most dimensions an offset in {-1,0,1}^N may move along.
+	reach int
+	// stencil, if set, lists the offsets instead of reach.
+	stencil [][]int
+}
+
+var (
+	// VonNeumann connects locations sharing a face: the 2N orthogonal neighbors.
+	VonNeumann = Neighborhood{name: "von Neumann", reach: 1}
+
+	// EdgeConnected connects locations sharing a face or an edge but not only a
+	// corner. It matches Moore in 2 dimensions and has 18 neighbors in 3.
+	EdgeConnected = Neighborhood{name: "edge connected", reach: 2}
+
+	// Moore connects locations sharing any boundary: all 3^N-1 locations
+	// surrounding a location, diagonals included.
+	Moore = Neighborhood{name: "Moore", reach: -1}
+)
+
+// Stencil returns a Neighborhood of the given offsets from a location. Since
+// connection goes both ways, the negation of every offset is included too.
+func Stencil(offsets ...[]int) Neighborhood {
+
+	stencil := make([][]int, len(offsets))
+	for i, offset := range offsets {
+		stencil[i] = append([]int{}, offset...)
+	}
+
+	return Neighborhood{name: "stencil", stencil: stencil}
+}
+
+func (n Neighborhood) String() string {
+	return n.name
+}
+
+// offsets returns the neighbor offsets of n in dims dimensions, without
+// duplicates and ordered so that von Neumann neighbors come by dimension and
+// then lower before upper.
+func (n Neighborhood) offsets(dims int) ([][]int, error) {
+
+	if n.stencil != nil {
+		return n.stencilOffsets(dims)
+	}
+
+	reach := n.reach
+	if reach < 0 || reach > dims {
+		reach = dims
+	}
+
+	var offsets [][]int
+	for k := 1; k <= reach; k++ {
+		for _, axes := range combinations(dims, k) {
+			// Each of the 2^k sign patterns, lower before upper per axis.
+			for signs := 0; signs < 1<<uint(k); signs++ {
+				offset := make([]int, dims)
+				for j, axis := range axes {
+					offset[axis] = -1
+					if signs&(1<<uint(k-1-j)) != 0 {
+						offset[axis] = 1
+					}
+				}
+				offsets = append(offsets, offset)
+			}
+		}
+	}
+
+	return offsets, nil
+}
+
+func (n Neighborhood) stencilOffsets(dims int) ([][]int, error) {
+
+	var offsets [][]int
+	seen := map[string]bool{}
+	add := func(offset []int) {
+		k := fmt.Sprint(offset)
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		offsets = append(offsets, offset)
+	}
+
+	for _, offset := range n.stencil {
+		if len(offset) != dims {
+			return nil, fmt.Errorf("stencil offset %v in %d dimensions: %w", offset, dims, ErrInvalidOption)
+		}
+		zero := true
+		for _, d := range offset {
+			if d != 0 {
+				zero = false
+			}
+		}
+		if zero {
+			return nil, fmt.Errorf("stencil offset %v connects a location to itself: %w", offset, ErrInvalidOption)
+		}
+		add(offset)
+	}
+
+	// Add every missing negation after the given offsets.
+	for _, offset := range offsets {
+		negated := make([]int, dims)
+		for d := range offset {
+			negated[d] = -offset[d]
+		}
+		add(negated)
+	}
+
+	return offsets, nil
+}
+
+// combinations returns every k element subset of [0, n) in lexicographic order.
+func combinations(n, k int) [][]int {
+
+	var all [][]int
+	var pick func(start int, chosen []int)
+	pick = func(start int, chosen []int) {
+		if len(chosen) == k {
+			all = append(all, append([]int{}, chosen...))
+			return
+		}
+		for i := start; i < n; i++ {
+			pick(i+1, append(chosen, i))
+		}
+	}
+	pick(0, nil)
+
+	return all
+}
@@ -0,0 +1,118 @@
+package orth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNeighborhood_offsets(t *testing.T) {
+	type args struct {
+		dims int
+	}
+	tests := []struct {
+		name      string
+		n         Neighborhood
+		args      args
+		want      [][]int
+		wantCount int
+		wantErr   error
+	}{
+		{
+			name: "von Neumann 2D",
+			n:    VonNeumann,
+			args: args{dims: 2},
+			want: [][]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}},
+		},
+		{
+			name: "Moore 2D",
+			n:    Moore,
+			args: args{dims: 2},
+			want: [][]int{
+				{-1, 0}, {1, 0}, {0, -1}, {0, 1},
+				{-1, -1}, {-1, 1}, {1, -1}, {1, 1},
+			},
+		},
+		{
+			name:      "von Neumann 4D",
+			n:         VonNeumann,
+			args:      args{dims: 4},
+			wantCount: 8,
+		},
+		{
+			name:      "edge connected 3D",
+			n:         EdgeConnected,
+			args:      args{dims: 3},
+			wantCount: 18,
+		},
+		{
+			name:      "edge connected 4D",
+			n:         EdgeConnected,
+			args:      args{dims: 4},
+			wantCount: 32,
+		},
+		{
+			name:      "Moore 3D",
+			n:         Moore,
+			args:      args{dims: 3},
+			wantCount: 26,
+		},
+		{
+			name:      "Moore 4D",
+			n:         Moore,
+			args:      args{dims: 4},
+			wantCount: 80,
+		},
+		{
+			name: "stencil adds negations",
+			n:    Stencil([]int{2, 0}, []int{1, 1}, []int{-1, -1}),
+			args: args{dims: 2},
+			want: [][]int{{2, 0}, {1, 1}, {-1, -1}, {-2, 0}},
+		},
+		{
+			name:    "stencil wrong dimensions",
+			n:       Stencil([]int{1, 0, 0}),
+			args:    args{dims: 2},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "stencil zero offset",
+			n:       Stencil([]int{0, 0}),
+			args:    args{dims: 2},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.n.offsets(tt.args.dims)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Neighborhood.offsets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Neighborhood.offsets() = %v, want %v", got, tt.want)
+			}
+			if tt.wantCount != 0 && len(got) != tt.wantCount {
+				t.Errorf("len(Neighborhood.offsets()) = %d, want %d", len(got), tt.wantCount)
+			}
+		})
+	}
+}
+
+func Test_combinations(t *testing.T) {
+	tests := []struct {
+		name string
+		n, k int
+		want [][]int
+	}{
+		{name: "none", n: 3, k: 0, want: [][]int{{}}},
+		{name: "pairs", n: 3, k: 2, want: [][]int{{0, 1}, {0, 2}, {1, 2}}},
+		{name: "all", n: 2, k: 2, want: [][]int{{0, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := combinations(tt.n, tt.k); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("combinations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
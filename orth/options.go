@@ -0,0 +1,97 @@
+package orth
+
+// Option configures an Orthotope created by New.
+type Option func(*config)
+
+// config collects the choices made by Options before New builds an Orthotope.
+type config struct {
+	neighborhood Neighborhood
+	boundaries   []Boundary
+	rule         SpanningRule
+	source       Source
+	field        Field
+	weights      []float64
+	direction    Direction
+	eventLog     bool
+}
+
+func newConfig(opts []Option) config {
+
+	c := config{
+		neighborhood: VonNeumann,
+		rule:         SpanAxis(0),
+		source:       globalSource{},
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// WithNeighborhood sets which locations count as neighbors. The default is
+// VonNeumann.
+func WithNeighborhood(n Neighborhood) Option {
+	return func(c *config) {
+		c.neighborhood = n
+	}
+}
+
+// WithBoundaries sets the Boundary of each dimension in order. The default is
+// Open in every dimension.
+func WithBoundaries(b ...Boundary) Option {
+	return func(c *config) {
+		c.boundaries = append([]Boundary{}, b...)
+	}
+}
+
+// WithSpanningRule sets when the bridge is complete. The default is
+// SpanAxis(0).
+func WithSpanningRule(r SpanningRule) Option {
+	return func(c *config) {
+		c.rule = r
+	}
+}
+
+// WithSource sets where BuildRandom and DemolishRandom draw their choices
+// from. The default is the math/rand top-level functions.
+func WithSource(src Source) Option {
+	return func(c *config) {
+		c.source = src
+	}
+}
+
+// WithField sets the weight of each location to that given by f. The default
+// is the same weight everywhere.
+func WithField(f Field) Option {
+	return func(c *config) {
+		c.field = f
+		c.weights = nil
+	}
+}
+
+// WithWeights sets the weight of each location from weights, one per location
+// in row-major order, where the last dimension varies fastest.
+func WithWeights(weights []float64) Option {
+	return func(c *config) {
+		c.field = nil
+		c.weights = append([]float64{}, weights...)
+	}
+}
+
+// WithDirection sets which way paths of bridges may run for BridgeComplete.
+// The default is Undirected. Any other Direction takes the place of the
+// SpanningRule, which must then be left at its default.
+func WithDirection(d Direction) Option {
+	return func(c *config) {
+		c.direction = d
+	}
+}
+
+// WithEventLog makes the Orthotope record every change it goes through, in
+// order, as returned by Events.
+func WithEventLog() Option {
+	return func(c *config) {
+		c.eventLog = true
+	}
+}
@@ -3,59 +3,156 @@ package orth
 import (
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"math"
 )
 
 var (
 	ErrOccupied      = errors.New("space occupied by bridge")
 	ErrOutOfBounds   = errors.New("out of bounds")
 	ErrInternalState = errors.New("bridge piece was destroyed")
+	ErrTooLarge      = errors.New("orthotope too large")
+	ErrNoPath        = errors.New("no spanning path")
+	ErrInvalidOption = errors.New("invalid option")
+	ErrNotAdjacent   = errors.New("locations not adjacent")
 )
 
+// maxCells is the largest number of locations an Orthotope can index.
+const maxCells = math.MaxInt32
+
+// maxDims is the most dimensions an orthotope can have, so that three flags per
+// dimension fit in the uint64 flags of a set of locations.
+const maxDims = 21
+
 // Orthotope represents an orthotope in N = len(Lengths) dimensions with side lengths
 // n_1 = Lengths[0], n_2 = Lengths[1], ..., n_N = Lengths[N-1]
 //
+// Locations are stored by their row-major index, where the last dimension
+// varies fastest. built marks which indices hold a bridge and cells partitions
+// every index so that cells[:nBuilt] are bridges and cells[nBuilt:] are not,
+// letting a random bridge or non-bridge be picked in constant time.
+//
 // Invariant:
-// - bridges U nonBridges: all integer locations within the orthotope
-// - len(bridges U nonBridges) = len(bridges + nonBridges)
+//   - built.get(i) iff position[i] < nBuilt
+//   - cells[position[i]] = i for every index i
+//   - two bridges share a set in components iff they are connected through
+//     neighboring bridges
+//   - every non-bridge is alone in its set in components
+//   - tally counts the sets in components spanning each dimension
+//   - if weights is set, free holds weights[i] for unoccupied i and 0 for
+//     bridges
+//
+// Each root in components carries flags recording which faces its bridges
+// touch and which periodic dimensions they wrap around, so completion is a
+// matter of reading tally.
+//
+// A location's node in components is the location itself until a bridge is
+// first demolished. From then on node maps each location to its node, since
+// the bridges split off a set get fresh nodes while their old ones stay behind
+// in the tree of the rest.
 type Orthotope struct {
-	Lengths    []int
-	bridges    map[string]bool
-	nonBridges map[string]bool
+	lattice
+	offsets    [][]int
+	steps      [][]int
+	rule       SpanningRule
+	source     Source
+	built      bitset
+	cells      []int32
+	position   []int32
+	nBuilt     int
+	components *forest
+	tally      tally
+
+	// weights, if set, holds the weight of every location and free the
+	// weights of the unoccupied ones.
+	weights []float64
+	free    fenwick
+
+	// directed, if set, tracks completion by directed paths instead.
+	directed *directedState
+
+	// logEvents is whether events logs every change in order.
+	logEvents bool
+	events    []Event
+
+	// node, stamp and searches are set up by the first demolition. stamp marks
+	// the locations visited by disconnect's searches, each stamped with epoch
+	// plus one more than the number of the search, searches holds each
+	// search's locations and groups joins the searches that met.
+	node     []int32
+	stamp    []uint32
+	epoch    uint32
+	searches [][]int
+	groups   []int
 }
 
-func New(lengths []int) (*Orthotope, error) {
+// New returns an Orthotope with side lengths lengths and no bridges.
+func New(lengths []int, opts ...Option) (*Orthotope, error) {
 
-	nonBridges := []string{""}
-	for _, length := range lengths {
-		var newNonBridges []string
-		for _, key := range nonBridges {
-			var newKeys []string
-			for i := 0; i < length; i++ {
-				newKey := fmt.Sprintf("%s-%d", key, i)
-				newKeys = append(newKeys, newKey)
-			}
-			newNonBridges = append(newNonBridges, newKeys...)
-		}
-		nonBridges = newNonBridges
+	c := newConfig(opts)
+	l, err := newLattice(lengths, c.boundaries)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, err := c.neighborhood.offsets(len(lengths))
+	if err != nil {
+		return nil, fmt.Errorf("neighborhood %v: %w", c.neighborhood, err)
 	}
 
-	if len(nonBridges) == 1 && nonBridges[0] == "" {
-		nonBridges = []string{}
+	if c.source == nil {
+		return nil, fmt.Errorf("nil source: %w", ErrInvalidOption)
 	}
 
-	nbs := map[string]bool{}
-	for _, nb := range nonBridges {
-		// Remove leading "-".
-		nb = nb[1:]
-		nbs[nb] = true
+	if err := c.rule.validate(l.periodic); err != nil {
+		return nil, fmt.Errorf("spanning rule %v: %w", c.rule, err)
+	}
+
+	directed, err := newDirected(c.direction, &l, offsets)
+	if err != nil {
+		return nil, err
+	}
+	// A Direction replaces the SpanningRule, so another rule would be ignored.
+	if directed != nil && c.rule != SpanAxis(0) {
+		return nil, fmt.Errorf("spanning rule %v with %v paths: %w", c.rule, c.direction, ErrInvalidOption)
+	}
+
+	weights := c.weights
+	if c.field != nil {
+		if weights, err = l.weights(c.field); err != nil {
+			return nil, fmt.Errorf("field: %w", err)
+		}
+	}
+	if weights != nil {
+		if err := l.checkWeights(weights); err != nil {
+			return nil, err
+		}
+	}
+
+	size := l.size
+	cells := make([]int32, size)
+	position := make([]int32, size)
+	for i := range cells {
+		cells[i] = int32(i)
+		position[i] = int32(i)
 	}
 
 	o := &Orthotope{
-		Lengths:    lengths,
-		bridges:    map[string]bool{},
-		nonBridges: nbs,
+		lattice:    l,
+		offsets:    offsets,
+		steps:      l.periodicSteps(offsets),
+		rule:       c.rule,
+		source:     c.source,
+		built:      newBitset(size),
+		cells:      cells,
+		position:   position,
+		components: newForest(size, l.countPeriodic()),
+		tally:      newTally(len(lengths)),
+		weights:    weights,
+		directed:   directed,
+		logEvents:  c.eventLog,
+	}
+	if weights != nil {
+		o.free = newFenwick(weights)
 	}
 	return o, nil
 }
@@ -63,276 +160,513 @@ func New(lengths []int) (*Orthotope, error) {
 // Build places a bridge at locs even if one already exists.
 func (o *Orthotope) Build(locs ...int) error {
 
-	if !o.inBound(locs...) {
+	if !o.hasCell(locs...) {
 		return fmt.Errorf("location %v outside bounds limits %v: %w", locs, o.Lengths, ErrOutOfBounds)
 	}
 
-	k := key(locs...)
-	o.bridges[k] = true
-	delete(o.nonBridges, k)
+	i := o.index(locs...)
+	if o.built.get(i) {
+		return nil
+	}
+	o.occupy(i)
 
 	return nil
 }
 
-// BuildRandom places a bridge at an unoccupied location and returns it as key.
+// BuildRandom places a bridge at an unoccupied location, chosen uniformly, or
+// in proportion to its weight if the Orthotope has a Field, by the
+// Orthotope's Source, and returns the location.
 func (o *Orthotope) BuildRandom() ([]int, error) {
+	return o.BuildRandomFrom(o.source)
+}
 
-	if len(o.nonBridges) == 0 {
+// BuildRandomFrom places a bridge at an unoccupied location, chosen uniformly,
+// or in proportion to its weight if the Orthotope has a Field, by src, and
+// returns the location.
+func (o *Orthotope) BuildRandomFrom(src Source) ([]int, error) {
+
+	free := len(o.cells) - o.nBuilt
+	if free == 0 {
 		return []int{}, fmt.Errorf("no more unocuppied space to build: %w", ErrInternalState)
 	}
 
 	// Select random unoccupied location
-	var nb string
-	for nb = range o.nonBridges {
-		break
+	var i int
+	if o.weights != nil {
+		var err error
+		if i, err = o.pick(src); err != nil {
+			return []int{}, err
+		}
+	} else {
+		i = int(o.cells[o.nBuilt+src.Intn(free)])
+	}
+	if o.built.get(i) {
+		return []int{}, fmt.Errorf("location %v in built locations: %w", o.coords(i), ErrInternalState)
 	}
+	o.occupy(i)
 
-	_, ok := o.bridges[nb]
-	if ok {
-		return []int{}, fmt.Errorf("location %v in built locations: %w", nb, ErrInternalState)
+	return o.coords(i), nil
+}
+
+// maxPicks is how many times pick draws before concluding rounding has left
+// only unoccupied locations of weight 0.
+const maxPicks = 64
+
+// pick returns an unoccupied index chosen by src in proportion to its weight.
+// Rounding in free may rarely land a draw on a bridge or a location of weight
+// 0, so such draws are redrawn.
+func (o *Orthotope) pick(src Source) (int, error) {
+
+	for k := 0; k < maxPicks; k++ {
+		total := o.free.total()
+		if !(total > 0) {
+			break
+		}
+		i := o.free.search(uniform(src) * total)
+		if i < len(o.cells) && !o.built.get(i) && o.weights[i] > 0 {
+			return i, nil
+		}
 	}
 
-	delete(o.nonBridges, nb)
-	o.bridges[nb] = true
+	return 0, fmt.Errorf("no unoccupied location has weight: %w", ErrInternalState)
+}
 
-	locs, err := locations(nb)
-	if err != nil {
-		return []int{}, fmt.Errorf("failed to build bridge in %v because: %w", nb, err)
+// occupy turns the unoccupied index i into a bridge.
+func (o *Orthotope) occupy(i int) {
+
+	// Swap i to the front of the unoccupied cells and grow the built prefix over it.
+	p := int(o.position[i])
+	j := int(o.cells[o.nBuilt])
+	o.cells[p], o.cells[o.nBuilt] = int32(j), int32(i)
+	o.position[j], o.position[i] = int32(p), int32(o.nBuilt)
+	o.nBuilt++
+	o.built.set(i)
+	if o.weights != nil {
+		o.free.add(i, -o.weights[i])
 	}
 
-	return locs, nil
+	o.connect(i)
+	if o.directed != nil {
+		o.reach(i)
+	}
+	o.record(OpBuild, i)
 }
 
-// Built returns whether the hypercube at locs contains a bridge.
-func (o *Orthotope) Built(locs ...int) (bool, error) {
+// connect joins the newly built bridge at index i with its bridge neighbors.
+func (o *Orthotope) connect(i int) {
 
-	if !o.inBound(locs...) {
-		return false, fmt.Errorf("location %v outside bounds limits %v: %w", locs, o.Lengths, ErrOutOfBounds)
+	x := o.slot(i)
+	o.components.reset(x, o.faces(i))
+	o.count(x, 1)
+	o.link(i)
+}
+
+// slot returns the node of index i in components.
+func (o *Orthotope) slot(i int) int {
+
+	if o.node == nil {
+		return i
 	}
 
-	k := key(locs...)
-	b, ok := o.bridges[k]
-	if !ok {
-		return false, nil
+	return int(o.node[i])
+}
+
+// root returns the root of the set in components holding index i.
+func (o *Orthotope) root(i int) int {
+	return o.components.find(o.slot(i))
+}
+
+// link joins the bridge at index i with each of its bridge neighbors. Unlike
+// neighbors, a location reached by several offsets around a periodic
+// dimension is joined once per offset, since those are distinct loops.
+func (o *Orthotope) link(i int) {
+
+	var scratch [8]int
+	locs := o.appendCoords(scratch[:0], i)
+	for k, offset := range o.offsets {
+		j, ok := o.step(i, locs, offset)
+		if ok && o.built.get(j) {
+			o.join(i, j, o.steps[k])
+		}
 	}
+}
 
-	// Having an unbuilt bridge location is against invariant.
-	if !b {
-		return false, fmt.Errorf("location %v: %w", locs, ErrInternalState)
+// join merges the sets of bridges a and b, where b lies at step from a along
+// the periodic dimensions, keeping spanned up to date.
+func (o *Orthotope) join(a, b int, step []int) {
+
+	ra := o.root(a)
+	rb := o.root(b)
+	o.count(ra, -1)
+	if rb != ra {
+		o.count(rb, -1)
 	}
 
-	return true, nil
+	r, winds := o.components.link(o.slot(a), o.slot(b), step)
+	o.components.mark(r, o.windFlags(winds))
+	o.count(r, 1)
 }
 
-// Neighbors returns the orthogonal neighbors of the hypercube at location locs.
-func (o *Orthotope) Neighbors(locs ...int) ([][]int, error) {
+// Demolish removes the bridge at locs if one exists.
+func (o *Orthotope) Demolish(locs ...int) error {
 
-	var neighbors [][]int
-	if !o.inBound(locs...) {
-		return neighbors, fmt.Errorf("location %v, %w", locs, ErrOutOfBounds)
+	if !o.hasCell(locs...) {
+		return fmt.Errorf("location %v outside bounds limits %v: %w", locs, o.Lengths, ErrOutOfBounds)
 	}
 
-	for i := range locs {
-		var l []int
-		var r []int
-		for _, loc := range locs {
-			l = append(l, loc)
-			r = append(r, loc)
-		}
-		l[i] -= 1
-		r[i] += 1
-		neighbors = append(neighbors, l)
-		neighbors = append(neighbors, r)
-	}
-
-	// Remove neighbors out of bounds
-	var inBoundNeighbors [][]int
-	for _, neighbor := range neighbors {
-		nKey := key(neighbor...)
-		_, okB := o.bridges[nKey]
-		_, okN := o.nonBridges[nKey]
-		in := o.inBound(neighbor...)
-		if in && !okB && !okN {
-			return neighbors, fmt.Errorf("in bound piece %v not in bridge or nonBridge sets: %w", neighbor, ErrInternalState)
-		}
-		if !in && (okB || okN) {
-			return neighbors, fmt.Errorf("out of bound piece %v in bridge (%v) or nonBridge (%v) sets: %w", neighbor, okB, okN, ErrInternalState)
+	i := o.index(locs...)
+	if !o.built.get(i) {
+		return nil
+	}
+	o.vacate(i)
+
+	return nil
+}
+
+// DemolishRandom removes a bridge at a built location, chosen uniformly by the
+// Orthotope's Source, and returns the location.
+func (o *Orthotope) DemolishRandom() ([]int, error) {
+	return o.DemolishRandomFrom(o.source)
+}
+
+// DemolishRandomFrom removes a bridge at a built location, chosen uniformly by
+// src, and returns the location.
+func (o *Orthotope) DemolishRandomFrom(src Source) ([]int, error) {
+
+	if o.nBuilt == 0 {
+		return []int{}, fmt.Errorf("no more bridges to demolish: %w", ErrInternalState)
+	}
+
+	// Select random built location
+	i := int(o.cells[src.Intn(o.nBuilt)])
+	if !o.built.get(i) {
+		return []int{}, fmt.Errorf("location %v not in built locations: %w", o.coords(i), ErrInternalState)
+	}
+	o.vacate(i)
+
+	return o.coords(i), nil
+}
+
+// vacate turns the bridge at index i into an unoccupied location.
+func (o *Orthotope) vacate(i int) {
+
+	// Swap i to the back of the bridges and shrink the built prefix off it.
+	o.nBuilt--
+	p := int(o.position[i])
+	j := int(o.cells[o.nBuilt])
+	o.cells[p], o.cells[o.nBuilt] = int32(j), int32(i)
+	o.position[j], o.position[i] = int32(p), int32(o.nBuilt)
+	o.built.clear(i)
+	if o.weights != nil {
+		o.free.add(i, o.weights[i])
+	}
+
+	o.disconnect(i)
+	if o.directed != nil {
+		o.unreach(i)
+	}
+	o.record(OpDemolish, i)
+}
+
+// disconnect splits the set that held the demolished bridge at index i into
+// its remaining connected parts. A union-find forest cannot delete, so the
+// pieces are searched for out of every bridge neighbor of i at once. Once all
+// but one piece are found, only those are joined again from scratch in fresh
+// nodes, leaving the rest, however large, as it was. A set winding around a
+// periodic dimension may stop winding without i, which only a search of all
+// of it tells, so such a set is joined again in full.
+func (o *Orthotope) disconnect(i int) {
+
+	if o.node == nil {
+		o.rebuild()
+		return
+	}
+
+	r := o.root(i)
+	flags := o.components.flags(r)
+	o.count(r, -1)
+
+	winds := flags>>(2*uint(len(o.Lengths))) != 0
+	kept := o.split(i, winds)
+	if kept < 0 {
+		// Every piece was found, so every node of the set is joined again in
+		// place.
+		o.components.reset(r, 0)
+		o.components.reset(o.slot(i), 0)
+		for _, q := range o.searches {
+			for _, m := range q {
+				x := o.slot(m)
+				o.components.reset(x, o.faces(m))
+				o.count(x, 1)
+			}
 		}
-		if okB && okN {
-			return neighbors, fmt.Errorf("piece %v in bridge and nonBridge sets: %w", neighbor, ErrInternalState)
+		o.relink(-1)
+		return
+	}
+
+	// The nodes of i and of the pieces found may hold up the tree of the kept
+	// piece, so they stay in it as it was and the locations move to fresh
+	// ones.
+	o.components.drop(r, o.faces(i))
+	o.node[i] = int32(o.components.add(0))
+	for s, q := range o.searches {
+		if o.group(s) == kept {
+			continue
 		}
-		if okB || okN {
-			inBoundNeighbors = append(inBoundNeighbors, neighbor)
+		for _, m := range q {
+			o.components.drop(r, o.faces(m))
+			x := o.components.add(o.faces(m))
+			o.node[m] = int32(x)
+			o.count(x, 1)
 		}
 	}
-	neighbors = inBoundNeighbors
+	o.count(r, 1)
+	o.relink(kept)
 
-	return neighbors, nil
+	// Reclaim the nodes left behind once they would take up a fifth of
+	// components.
+	if len(o.components.parent) >= len(o.cells)+len(o.cells)/4+16 {
+		o.rebuild()
+	}
 }
 
-// BridgeComplete returns true if there is an orthogonally connected path
-// from 0 to o.Lengths[0]-1 along the 1st dimension.
-func (o *Orthotope) BridgeComplete() (bool, error) {
+// relink links every location found by the searches outside the piece kept.
+func (o *Orthotope) relink(kept int) {
 
-	visited := map[string]bool{}
-	for k := range o.bridges {
-		if _, ok := visited[k]; ok {
+	for s, q := range o.searches {
+		if kept >= 0 && o.group(s) == kept {
 			continue
 		}
+		for _, m := range q {
+			o.link(m)
+		}
+	}
+}
 
-		// BFS
-		left := false
-		right := false
-		var q []string
-		q = append(q, k)
-		for len(q) > 0 {
-			cur := q[0]
-			q = q[1:]
-
-			// Skip visited locations
-			if _, ok := visited[cur]; ok {
-				continue
-			}
-			visited[cur] = true
+// split searches the bridges of the set that held the demolished bridge at
+// index i out of each of its bridge neighbors in lockstep, one location per
+// search in turn. Searches that meet are in one piece and searches that run
+// out have found all of theirs. It stops once every piece is found or, unless
+// all, once a single piece is still being searched, and returns the group of
+// that piece, or -1. searches is left holding the locations each search found.
+func (o *Orthotope) split(i int, all bool) int {
+
+	var buf []int
+	seeds := o.neighbors(i, nil)
+	n := 0
+	for _, s := range seeds {
+		if o.built.get(s) {
+			seeds[n] = s
+			n++
+		}
+	}
+	seeds = seeds[:n]
 
-			loc, err := locations(cur)
-			if err != nil {
-				return false, fmt.Errorf("failed to turn key %q into lcoation: %w", cur, err)
-			}
+	if o.epoch > math.MaxUint32-uint32(n)-1 {
+		for k := range o.stamp {
+			o.stamp[k] = 0
+		}
+		o.epoch = 0
+	}
+	for len(o.searches) < n {
+		o.searches = append(o.searches, nil)
+	}
+	o.searches = o.searches[:n]
+	groups := make([]int, n)
+	open := make([]int, n)
+	head := make([]int, n)
+	for s, seed := range seeds {
+		o.stamp[seed] = o.epoch + 1 + uint32(s)
+		o.searches[s] = append(o.searches[s][:0], seed)
+		groups[s] = s
+		open[s] = 1
+	}
+	o.groups = groups
 
-			// Check if we've reached both sides
-			if loc[0] == 0 {
-				left = true
-			}
-			if loc[0] == o.Lengths[0]-1 {
-				right = true
-			}
-			if left && right {
-				return true, nil
+	limit := 1
+	if all {
+		limit = 0
+	}
+	searching := n
+	for searching > limit {
+		for s := range o.searches {
+			q := o.searches[s]
+			if head[s] == len(q) {
+				continue
 			}
-
-			// Get neighbors and add to queue
-			neighbors, err := o.Neighbors(loc...)
-			if err != nil {
-				return false, fmt.Errorf("failed to generate neighbors from %v into lcoation: %w", loc, err)
+			cur := q[head[s]]
+			head[s]++
+			buf = o.neighbors(cur, buf[:0])
+			for _, m := range buf {
+				if !o.built.get(m) {
+					continue
+				}
+				if st := o.stamp[m]; st > o.epoch {
+					a, b := o.group(s), o.group(int(st-o.epoch-1))
+					if a != b {
+						groups[b] = a
+						open[a] += open[b]
+						searching--
+					}
+					continue
+				}
+				o.stamp[m] = o.epoch + 1 + uint32(s)
+				q = append(q, m)
 			}
-
-			for _, n := range neighbors {
-				nk := key(n...)
-				if bridge, ok := o.bridges[nk]; bridge && ok {
-					q = append(q, nk)
+			o.searches[s] = q
+			if head[s] == len(q) {
+				g := o.group(s)
+				open[g]--
+				if open[g] == 0 {
+					searching--
 				}
 			}
 		}
 	}
+	o.epoch += uint32(n)
 
-	return false, nil
+	for s := range o.searches {
+		if g := o.group(s); open[g] > 0 {
+			return g
+		}
+	}
+
+	return -1
 }
 
-func (o *Orthotope) String() string {
+// group returns the group of search s in the last split.
+func (o *Orthotope) group(s int) int {
 
-	switch len(o.Lengths) {
-	case 0:
-		return ""
-	case 1:
-		return o.string1D()
-	case 2:
-		return o.string2D()
-	default:
-		return "HIGHER DIMENSIONS UNSUPPORTED"
+	for o.groups[s] != s {
+		o.groups[s] = o.groups[o.groups[s]]
+		s = o.groups[s]
 	}
+
+	return s
 }
 
-func (o *Orthotope) string1D() string {
+// rebuild joins every bridge again from scratch in fresh components, counting
+// the faces each touches so that disconnect can take them out again, and sets
+// up what disconnect needs.
+func (o *Orthotope) rebuild() {
 
-	var str string
-	for i := 0; i < o.Lengths[0]; i++ {
-		k := key(i)
-		b, ok := o.bridges[k]
-		s := "."
-		if b && ok {
-			s = "B"
-		}
-		str += " " + s
+	n := len(o.cells)
+	if o.node == nil {
+		o.node = make([]int32, n)
+		o.stamp = make([]uint32, n)
+	}
+	for i := range o.node {
+		o.node[i] = int32(i)
+	}
+	o.components = newForest(n, o.countPeriodic())
+	o.components.count(2 * uint(len(o.Lengths)))
+	o.tally = newTally(len(o.Lengths))
+
+	for _, c := range o.cells[:o.nBuilt] {
+		i := int(c)
+		o.components.reset(i, o.faces(i))
+		o.count(i, 1)
+	}
+	for _, c := range o.cells[:o.nBuilt] {
+		o.link(int(c))
 	}
+}
 
-	return str
+// Built returns whether the hypercube at locs contains a bridge.
+func (o *Orthotope) Built(locs ...int) (bool, error) {
+
+	if !o.hasCell(locs...) {
+		return false, fmt.Errorf("location %v outside bounds limits %v: %w", locs, o.Lengths, ErrOutOfBounds)
+	}
+
+	i := o.index(locs...)
+	b := o.built.get(i)
+
+	// A bridge outside the built partition, or vice versa, is against invariant.
+	if b != (int(o.position[i]) < o.nBuilt) {
+		return false, fmt.Errorf("location %v: %w", locs, ErrInternalState)
+	}
+
+	return b, nil
 }
 
-func (o *Orthotope) string2D() string {
+// Neighbors returns the neighbors of the hypercube at location locs under the
+// Orthotope's Neighborhood, orthogonal ones by default, wrapping around
+// Periodic dimensions.
+func (o *Orthotope) Neighbors(locs ...int) ([][]int, error) {
 
-	n1Max := o.Lengths[0]
-	n2Max := o.Lengths[1]
+	var neighbors [][]int
+	if !o.inBound(locs...) {
+		return neighbors, fmt.Errorf("location %v, %w", locs, ErrOutOfBounds)
+	}
 
-	var str string
-	for n2 := 0; n2 < n2Max; n2++ {
-		for n1 := 0; n1 < n1Max; n1++ {
-			k := key(n1, n2)
-			b, ok := o.bridges[k]
-			s := "."
-			if b && ok {
-				s = "B"
-			}
-			str += " " + s
-		}
-		str += "\n"
+	for _, n := range o.neighbors(o.index(locs...), nil) {
+		neighbors = append(neighbors, o.coords(n))
 	}
 
-	return str
+	return neighbors, nil
 }
 
-func (o *Orthotope) inBound(locs ...int) bool {
+// neighbors appends the indices of the in bound neighbors of index i to buf,
+// in the order of o.offsets. Periodic dimensions wrap around, and a location
+// reached twice that way, or i itself, is only listed once or not at all.
+func (o *Orthotope) neighbors(i int, buf []int) []int {
 
-	for i, loc := range locs {
-		// Location containers higher dimension
-		if i >= len(o.Lengths) {
-			return false
-		}
+	var scratch [8]int
+	locs := o.appendCoords(scratch[:0], i)
+	start := len(buf)
 
-		length := o.Lengths[i]
-		if loc < 0 || loc >= length {
-			return false
+	for _, offset := range o.offsets {
+		j, ok := o.step(i, locs, offset)
+		if !ok || (o.wraps && !o.fresh(i, j, buf[start:])) {
+			continue
 		}
+		buf = append(buf, j)
 	}
 
-	return true
+	return buf
 }
 
-// key returns ths string representation of locs.
-// Example: [1,2,3] -> "1-2-3"
-func key(locs ...int) string {
+// fresh returns whether the neighbor j of i is neither i nor already in found.
+func (o *Orthotope) fresh(i, j int, found []int) bool {
 
-	if len(locs) == 0 {
-		return ""
+	if j == i {
+		return false
 	}
-
-	var locKey string
-	for _, loc := range locs {
-		locKey += fmt.Sprintf("%d-", loc)
+	for _, f := range found {
+		if f == j {
+			return false
+		}
 	}
 
-	// Remove traling "-"
-	locKey = locKey[:len(locKey)-1]
-	return locKey
+	return true
 }
 
-// locations returns the slice representation of key.
-// Example: "1-2-3" -> [1,2,3]
-func locations(key string) ([]int, error) {
+func (o *Orthotope) String() string {
 
-	var locs []int
-	if len(key) == 0 {
-		return locs, nil
+	switch len(o.Lengths) {
+	case 0:
+		return ""
+	case 1:
+		return o.string1D()
+	case 2:
+		return o.plane(0, 1, []int{0, 0})
+	default:
+		s, _ := o.Slices()
+		return s
 	}
+}
 
-	locStings := strings.Split(key, "-")
-	for _, s := range locStings {
-		loc, err := strconv.Atoi(s)
-		if err != nil {
-			return locs, fmt.Errorf("invalid key format %q, must be int", s)
+func (o *Orthotope) string1D() string {
+
+	var str string
+	for i := 0; i < o.Lengths[0]; i++ {
+		s := "."
+		if o.built.get(o.index(i)) {
+			s = "B"
 		}
-		locs = append(locs, loc)
+		str += " " + s
 	}
 
-	return locs, nil
+	return str
 }
@@ -1,117 +1,319 @@
 package orth
 
 import (
+	"errors"
+	"math"
+	"math/rand"
 	"reflect"
 	"testing"
 )
 
-var (
-	twoD = map[string]bool{
-		"0-0": true,
-		"0-1": true,
-		"0-2": true,
-		"0-3": true,
+// newTestOrthotope returns an Orthotope over lengths configured by opts with
+// bridges built at locs.
+func newTestOrthotope(t *testing.T, lengths []int, opts []Option, locs ...[]int) *Orthotope {
+	t.Helper()
 
-		"1-0": true,
-		"1-1": true,
-		"1-2": true,
-		"1-3": true,
+	o, err := New(lengths, opts...)
+	if err != nil {
+		t.Fatalf("New(%v) error = %v", lengths, err)
+	}
+	for _, l := range locs {
+		if err := o.Build(l...); err != nil {
+			t.Fatalf("Orthotope.Build(%v) error = %v", l, err)
+		}
+	}
+
+	return o
+}
+
+// bridgeLocations returns the location of every bridge in row-major order.
+func bridgeLocations(o *Orthotope) [][]int {
 
-		"2-0": true,
-		"2-1": true,
-		"2-2": true,
-		"2-3": true,
+	var locs [][]int
+	for i := 0; i < len(o.cells); i++ {
+		if o.built.get(i) {
+			locs = append(locs, o.coords(i))
+		}
 	}
 
-	threeD = map[string]bool{
-		"0-0-0": true,
-		"0-0-1": true,
+	return locs
+}
 
-		"0-1-0": true,
-		"0-1-1": true,
+// checkInvariants fails t if o's storage disagrees with itself.
+func checkInvariants(t *testing.T, o *Orthotope) {
+	t.Helper()
 
-		"1-0-0": true,
-		"1-0-1": true,
+	var built int
+	for i := range o.cells {
+		if int(o.cells[o.position[i]]) != i {
+			t.Fatalf("cells[position[%d]] = %d, want %d", i, o.cells[o.position[i]], i)
+		}
+		b := o.built.get(i)
+		if b != (int(o.position[i]) < o.nBuilt) {
+			t.Fatalf("built.get(%d) = %v but position %d, nBuilt %d", i, b, o.position[i], o.nBuilt)
+		}
+		if b {
+			built++
+		}
+	}
+	if built != o.nBuilt {
+		t.Fatalf("%d bridges, nBuilt = %d", built, o.nBuilt)
+	}
 
-		"1-1-0": true,
-		"1-1-1": true,
+	if o.weights != nil {
+		var free float64
+		for i, w := range o.weights {
+			if !o.built.get(i) {
+				free += w
+			}
+		}
+		if math.Abs(o.free.total()-free) > 1e-9*(1+free) {
+			t.Fatalf("free.total() = %v, want %v", o.free.total(), free)
+		}
+	}
+}
+
+// checkComponents fails t if the sets in o.components differ from the
+// connected groups of bridges found by searching.
+func checkComponents(t *testing.T, o *Orthotope) {
+	t.Helper()
+
+	label := map[int]int{}
+	var buf []int
+	for i := range o.cells {
+		if !o.built.get(i) {
+			if r := o.root(i); r != o.slot(i) {
+				t.Fatalf("non-bridge %v in set of %v", o.coords(i), o.coords(r))
+			}
+			continue
+		}
+		if _, ok := label[i]; ok {
+			continue
+		}
+		q := []int{i}
+		label[i] = i
+		for len(q) > 0 {
+			cur := q[0]
+			q = q[1:]
+			buf = o.neighbors(cur, buf[:0])
+			for _, n := range buf {
+				if _, ok := label[n]; o.built.get(n) && !ok {
+					label[n] = i
+					q = append(q, n)
+				}
+			}
+		}
 	}
-)
+
+	roots := map[int]int{}
+	for i, l := range label {
+		r := o.root(i)
+		if got, ok := roots[l]; ok && got != r {
+			t.Fatalf("bridges connected to %v split across sets", o.coords(l))
+		}
+		roots[l] = r
+	}
+	seen := map[int]bool{}
+	for _, r := range roots {
+		if seen[r] {
+			t.Fatalf("disconnected bridges share set of %v", o.coords(r))
+		}
+		seen[r] = true
+	}
+
+	sizes := map[int]int{}
+	faces := map[int]uint64{}
+	for i := range label {
+		r := o.root(i)
+		sizes[r]++
+		faces[r] |= o.faces(i)
+	}
+	facesMask := uint64(1)<<(2*uint(len(o.Lengths))) - 1
+	for r, size := range sizes {
+		if int(o.components.size[r]) != size {
+			t.Fatalf("set size %d, want %d", o.components.size[r], size)
+		}
+		if got := o.components.flags(r) & facesMask; got != faces[r] {
+			t.Fatalf("set faces %b, want %b", got, faces[r])
+		}
+	}
+
+	spanned := make([]int, len(o.Lengths))
+	var spannedAll int
+	for _, r := range roots {
+		mask := o.spans(o.components.flags(r))
+		for d := range spanned {
+			if mask&(1<<uint(d)) != 0 {
+				spanned[d]++
+			}
+		}
+		if mask != 0 && mask == 1<<uint(len(o.Lengths))-1 {
+			spannedAll++
+		}
+	}
+	if !reflect.DeepEqual(spanned, o.tally.spanned) || spannedAll != o.tally.all {
+		t.Fatalf("spanned = %v, %d all, want %v, %d all", o.tally.spanned, o.tally.all, spanned, spannedAll)
+	}
+
+	want, wantAll, err := o.spannedBFS()
+	if err != nil {
+		t.Fatalf("Orthotope.spannedBFS() error = %v", err)
+	}
+	if got := o.tally.axes(); got != want || (spannedAll > 0) != wantAll {
+		t.Fatalf("spanned axes %b, all %v, spannedBFS() = %b, all %v", got, spannedAll > 0, want, wantAll)
+	}
+}
 
 func TestNew(t *testing.T) {
 	type args struct {
 		lengths []int
+		opts    []Option
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    *Orthotope
-		wantErr bool
+		name      string
+		args      args
+		wantCells int
+		wantErr   error
 	}{
 		{
 			name: "0-D",
 			args: args{
 				lengths: []int{},
 			},
-			want: &Orthotope{
-				Lengths:    []int{},
-				bridges:    map[string]bool{},
-				nonBridges: map[string]bool{},
-			},
-			wantErr: false,
+			wantCells: 0,
 		},
 		{
 			name: "1-D",
 			args: args{
 				lengths: []int{3},
 			},
-			want: &Orthotope{
-				Lengths: []int{3},
-				bridges: map[string]bool{},
-				nonBridges: map[string]bool{
-					"0": true,
-					"1": true,
-					"2": true,
-				},
-			},
-			wantErr: false,
+			wantCells: 3,
 		},
 		{
 			name: "2-D",
 			args: args{
 				lengths: []int{3, 4},
 			},
-			want: &Orthotope{
-				Lengths:    []int{3, 4},
-				bridges:    map[string]bool{},
-				nonBridges: twoD,
-			},
-			wantErr: false,
+			wantCells: 12,
 		},
 		{
 			name: "3-D",
 			args: args{
 				lengths: []int{2, 2, 2},
 			},
-			want: &Orthotope{
-				Lengths:    []int{2, 2, 2},
-				bridges:    map[string]bool{},
-				nonBridges: threeD,
+			wantCells: 8,
+		},
+		{
+			name: "empty side",
+			args: args{
+				lengths: []int{3, 0},
+			},
+			wantCells: 0,
+		},
+		{
+			name: "negative side",
+			args: args{
+				lengths: []int{3, -1},
 			},
-			wantErr: false,
+			wantErr: ErrOutOfBounds,
+		},
+		{
+			name: "periodic",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Periodic)},
+			},
+			wantCells: 12,
+		},
+		{
+			name: "periodic 1st dimension",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Periodic, Open)},
+			},
+			wantCells: 12,
+		},
+		{
+			name: "spanning axis out of range",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithSpanningRule(SpanAxis(2))},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "wrapping without periodic dimension",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithSpanningRule(SpanWrapping)},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "nil source",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithSource(nil)},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "too many dimensions",
+			args: args{
+				lengths: make([]int, maxDims+1),
+			},
+			wantErr: ErrTooLarge,
+		},
+		{
+			name: "missing boundary",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open)},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "unknown boundary",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Boundary(7))},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "bad stencil",
+			args: args{
+				lengths: []int{3, 4},
+				opts:    []Option{WithNeighborhood(Stencil([]int{1}))},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "too large",
+			args: args{
+				lengths: []int{1 << 16, 1 << 16},
+			},
+			wantErr: ErrTooLarge,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := New(tt.args.lengths)
-			if (err != nil) != tt.wantErr {
+			got, err := New(tt.args.lengths, tt.args.opts...)
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("New() = %+v, want %+v", *got, *tt.want)
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Lengths, tt.args.lengths) {
+				t.Errorf("New().Lengths = %v, want %v", got.Lengths, tt.args.lengths)
+			}
+			if len(got.cells) != tt.wantCells || got.nBuilt != 0 {
+				t.Errorf("New() has %d cells with %d built, want %d with 0 built", len(got.cells), got.nBuilt, tt.wantCells)
 			}
+			checkInvariants(t, got)
 		})
 	}
 }
@@ -119,9 +321,8 @@ func TestNew(t *testing.T) {
 func TestOrthotope_Build(t *testing.T) {
 
 	type fields struct {
-		Lengths    []int
-		bridges    map[string]bool
-		nonBridges map[string]bool
+		Lengths []int
+		built   [][]int
 	}
 	type args struct {
 		locs []int
@@ -130,188 +331,510 @@ func TestOrthotope_Build(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    *Orthotope
+		want    [][]int
 		wantErr bool
 	}{
 		{
 			name: "2D build",
 			fields: fields{
-				Lengths:    []int{3, 4},
-				bridges:    map[string]bool{},
-				nonBridges: twoD,
+				Lengths: []int{3, 4},
 			},
 			args: args{
 				locs: []int{1, 2},
 			},
-			want: &Orthotope{
-				Lengths: []int{3, 4},
-				bridges: map[string]bool{
-					"1-2": true,
-				},
-				nonBridges: map[string]bool{
-					"0-0": true,
-					"0-1": true,
-					"0-2": true,
-					"0-3": true,
-
-					"1-0": true,
-					"1-1": true,
-					// "1-2": true, REMOVED
-					"1-3": true,
-
-					"2-0": true,
-					"2-1": true,
-					"2-2": true,
-					"2-3": true,
-				},
-			},
+			want:    [][]int{{1, 2}},
 			wantErr: false,
 		},
 		{
 			name: "3D build",
 			fields: fields{
-				Lengths:    []int{2, 2, 2},
-				bridges:    map[string]bool{},
-				nonBridges: threeD,
+				Lengths: []int{2, 2, 2},
+				built:   [][]int{{1, 1, 0}},
 			},
 			args: args{
 				locs: []int{0, 1, 0},
 			},
-			want: &Orthotope{
-				Lengths: []int{2, 2, 2},
-				bridges: map[string]bool{
-					"0-1-0": true,
-				},
-				nonBridges: map[string]bool{
-					"0-0-0": true,
-					"0-0-1": true,
+			want:    [][]int{{0, 1, 0}, {1, 1, 0}},
+			wantErr: false,
+		},
+		{
+			name: "already built",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{1, 2}},
+			},
+			args: args{
+				locs: []int{1, 2},
+			},
+			want:    [][]int{{1, 2}},
+			wantErr: false,
+		},
+		{
+			name: "out of bounds",
+			fields: fields{
+				Lengths: []int{3, 4},
+			},
+			args: args{
+				locs: []int{3, 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing dimension",
+			fields: fields{
+				Lengths: []int{3, 4},
+			},
+			args: args{
+				locs: []int{1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "0-D",
+			fields: fields{
+				Lengths: []int{},
+			},
+			args: args{
+				locs: []int{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			if err := o.Build(tt.args.locs...); (err != nil) != tt.wantErr {
+				t.Errorf("Orthotope.Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := bridgeLocations(o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.Build() -> %v, want %v", got, tt.want)
+			}
+			checkInvariants(t, o)
+		})
+	}
+}
 
-					// "0-1-0": true, REMOVED
-					"0-1-1": true,
+func TestOrthotope_BuildRandom(t *testing.T) {
 
-					"1-0-0": true,
-					"1-0-1": true,
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		want      []int
+		wantBuilt [][]int
+		wantErr   bool
+	}{
+		{
+			name: "single 1D",
+			fields: fields{
+				Lengths: []int{1},
+			},
+			want:      []int{0},
+			wantBuilt: [][]int{{0}},
+			wantErr:   false,
+		},
+		{
+			name: "double 1D",
+			fields: fields{
+				Lengths: []int{2},
+				built:   [][]int{{1}},
+			},
+			want:      []int{0},
+			wantBuilt: [][]int{{0}, {1}},
+			wantErr:   false,
+		},
+		{
+			name: "last 2D",
+			fields: fields{
+				Lengths: []int{2, 2},
+				built:   [][]int{{0, 0}, {1, 1}, {0, 1}},
+			},
+			want:      []int{1, 0},
+			wantBuilt: [][]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+			wantErr:   false,
+		},
+		{
+			name: "all occupied",
+			fields: fields{
+				Lengths: []int{2},
+				built:   [][]int{{0}, {1}},
+			},
+			want:      []int{},
+			wantBuilt: [][]int{{0}, {1}},
+			wantErr:   true,
+		},
+	}
 
-					"1-1-0": true,
-					"1-1-1": true,
-				},
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			got, err := o.BuildRandom()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Orthotope.BuildRandom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.BuildRandom() = %v, want %v", got, tt.want)
+			}
+			if gotBuilt := bridgeLocations(o); !reflect.DeepEqual(gotBuilt, tt.wantBuilt) {
+				t.Errorf("Orthotope.BuildRandom() -> %v, want %v", gotBuilt, tt.wantBuilt)
+			}
+			checkInvariants(t, o)
+		})
+	}
+}
+
+func TestOrthotope_BuildRandom_reproducible(t *testing.T) {
+
+	order := func(seed int64) [][]int {
+		o := newTestOrthotope(t, []int{6, 5, 4}, []Option{WithSource(rand.New(rand.NewSource(seed)))})
+		var got [][]int
+		for o.nBuilt < len(o.cells) {
+			locs, err := o.BuildRandom()
+			if err != nil {
+				t.Fatalf("Orthotope.BuildRandom() error = %v", err)
+			}
+			got = append(got, locs)
+		}
+		return got
+	}
+
+	if a, b := order(7), order(7); !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed built in different orders")
+	}
+	if a, b := order(7), order(8); reflect.DeepEqual(a, b) {
+		t.Errorf("different seeds built in the same order")
+	}
+}
+
+func TestOrthotope_BuildRandomFrom_uniform(t *testing.T) {
+
+	// chi2 is the 99.9th percentile of the chi-squared distribution with 11
+	// degrees of freedom.
+	const (
+		trials = 24000
+		chi2   = 31.26
+	)
+	lengths := []int{3, 4}
+	tests := []struct {
+		name  string
+		built [][]int
+		// before is how many random bridges to place before the counted one.
+		before int
+	}{
+		{name: "first pick"},
+		{name: "fifth pick", before: 4},
+		{name: "after Build and Demolish", built: [][]int{{0, 0}, {2, 3}, {1, 1}}, before: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(11))
+			counts := map[int]int{}
+			free := 0
+			for trial := 0; trial < trials; trial++ {
+				o := newTestOrthotope(t, lengths, nil, tt.built...)
+				for _, locs := range tt.built {
+					if err := o.Demolish(locs...); err != nil {
+						t.Fatalf("Orthotope.Demolish() error = %v", err)
+					}
+				}
+				for k := 0; k < tt.before; k++ {
+					if _, err := o.BuildRandomFrom(rng); err != nil {
+						t.Fatalf("Orthotope.BuildRandomFrom() error = %v", err)
+					}
+				}
+				locs, err := o.BuildRandomFrom(rng)
+				if err != nil {
+					t.Fatalf("Orthotope.BuildRandomFrom() error = %v", err)
+				}
+				counts[o.index(locs...)]++
+				free = len(o.cells)
+			}
+
+			// By symmetry every location is equally likely to be the counted pick.
+			want := float64(trials) / float64(free)
+			var stat float64
+			for i := 0; i < free; i++ {
+				diff := float64(counts[i]) - want
+				stat += diff * diff / want
+			}
+			if stat > chi2 {
+				t.Errorf("chi-squared = %.2f over %v, want at most %.2f", stat, counts, chi2)
+			}
+		})
+	}
+}
+
+func TestOrthotope_Demolish(t *testing.T) {
+
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	type args struct {
+		locs []int
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		want         [][]int
+		wantComplete bool
+		wantErr      bool
+	}{
+		{
+			name: "2D demolish",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{0, 1}, {1, 1}, {2, 1}},
+			},
+			args: args{
+				locs: []int{1, 1},
+			},
+			want:         [][]int{{0, 1}, {2, 1}},
+			wantComplete: false,
+			wantErr:      false,
+		},
+		{
+			name: "2D demolish with detour",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{0, 1}, {1, 1}, {2, 1}, {0, 2}, {1, 2}},
+			},
+			args: args{
+				locs: []int{0, 1},
+			},
+			want:         [][]int{{0, 2}, {1, 1}, {1, 2}, {2, 1}},
+			wantComplete: true,
+			wantErr:      false,
+		},
+		{
+			name: "not built",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{0, 1}},
+			},
+			args: args{
+				locs: []int{1, 1},
 			},
+			want:         [][]int{{0, 1}},
+			wantComplete: false,
+			wantErr:      false,
+		},
+		{
+			name: "out of bounds",
+			fields: fields{
+				Lengths: []int{3, 4},
+			},
+			args: args{
+				locs: []int{1, 4},
+			},
+			wantErr: true,
+		},
+		{
+			name: "0-D",
+			fields: fields{
+				Lengths: []int{},
+			},
+			args: args{
+				locs: []int{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			if err := o.Demolish(tt.args.locs...); (err != nil) != tt.wantErr {
+				t.Errorf("Orthotope.Demolish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := bridgeLocations(o); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.Demolish() -> %v, want %v", got, tt.want)
+			}
+			if got, _ := o.BridgeComplete(); got != tt.wantComplete {
+				t.Errorf("Orthotope.BridgeComplete() = %v, want %v", got, tt.wantComplete)
+			}
+			checkInvariants(t, o)
+			checkComponents(t, o)
+		})
+	}
+}
+
+func TestOrthotope_DemolishRandom(t *testing.T) {
+
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		want      []int
+		wantBuilt [][]int
+		wantErr   bool
+	}{
+		{
+			name: "single 1D",
+			fields: fields{
+				Lengths: []int{3},
+				built:   [][]int{{1}},
+			},
+			want:    []int{1},
 			wantErr: false,
 		},
+		{
+			name: "none built",
+			fields: fields{
+				Lengths: []int{3},
+			},
+			want:    []int{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			got, err := o.DemolishRandom()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Orthotope.DemolishRandom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.DemolishRandom() = %v, want %v", got, tt.want)
+			}
+			if gotBuilt := bridgeLocations(o); !reflect.DeepEqual(gotBuilt, tt.wantBuilt) {
+				t.Errorf("Orthotope.DemolishRandom() -> %v, want %v", gotBuilt, tt.wantBuilt)
+			}
+			checkInvariants(t, o)
+		})
+	}
+}
+
+func TestOrthotope_Demolish_matchesBFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+		// build is the chance each step builds rather than demolishes.
+		build float64
+	}{
+		{name: "1D", lengths: []int{6}, build: 0.6},
+		{name: "2D", lengths: []int{12, 9}, build: 0.55},
+		{name: "2D erode", lengths: []int{8, 8}, build: 0.45},
+		{name: "2D Moore", lengths: []int{12, 9}, opts: []Option{WithNeighborhood(Moore)}, build: 0.4},
+		{name: "3D", lengths: []int{5, 4, 3}, build: 0.5},
+		{name: "3D edge connected", lengths: []int{5, 4, 3}, opts: []Option{WithNeighborhood(EdgeConnected)}, build: 0.4},
+		{name: "3D knight stencil", lengths: []int{5, 4, 3}, opts: []Option{WithNeighborhood(Stencil([]int{1, 2, 0}, []int{0, 1, 2}))}, build: 0.5},
+		{name: "2D periodic", lengths: []int{12, 9}, opts: []Option{WithBoundaries(Open, Periodic)}, build: 0.5},
+		{name: "3D periodic Moore", lengths: []int{5, 4, 3}, opts: []Option{WithBoundaries(Open, Periodic, Periodic), WithNeighborhood(Moore)}, build: 0.35},
+		{name: "4D", lengths: []int{3, 3, 2, 3}, build: 0.5},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &Orthotope{
-				Lengths:    tt.fields.Lengths,
-				bridges:    tt.fields.bridges,
-				nonBridges: tt.fields.nonBridges,
-			}
-			if err := o.Build(tt.args.locs...); (err != nil) != tt.wantErr {
-				t.Errorf("Orthotope.Build() error = %v, wantErr %v", err, tt.wantErr)
-			}
-			if !reflect.DeepEqual(o, tt.want) {
-				t.Errorf("Orthotope.Build() -> %+v, want %+v", *o, *tt.want)
+			rng := rand.New(rand.NewSource(1))
+			o := newTestOrthotope(t, tt.lengths, tt.opts)
+			for step := 0; step < 20*len(o.cells); step++ {
+				var locs []int
+				var err error
+				if o.nBuilt == 0 || (o.nBuilt < len(o.cells) && rng.Float64() < tt.build) {
+					locs, err = o.BuildRandom()
+				} else {
+					locs, err = o.DemolishRandom()
+				}
+				if err != nil {
+					t.Fatalf("step %d: error = %v", step, err)
+				}
+				got, err := o.BridgeComplete()
+				if err != nil {
+					t.Fatalf("Orthotope.BridgeComplete() error = %v", err)
+				}
+				want, err := o.bridgeCompleteBFS()
+				if err != nil {
+					t.Fatalf("Orthotope.bridgeCompleteBFS() error = %v", err)
+				}
+				if got != want {
+					t.Fatalf("step %d at %v: Orthotope.BridgeComplete() = %v, bridgeCompleteBFS() = %v", step, locs, got, want)
+				}
+				checkComponents(t, o)
 			}
+			checkInvariants(t, o)
 		})
 	}
 }
 
-func TestOrthotope_BuildRandom(t *testing.T) {
-
-	type fields struct {
-		Lengths    []int
-		bridges    map[string]bool
-		nonBridges map[string]bool
-	}
+func TestOrthotope_Demolish_local(t *testing.T) {
 	tests := []struct {
-		name    string
-		fields  fields
-		want    []int
-		wantO   *Orthotope
-		wantErr bool
+		name string
+		// block is the side of a square of bridges at the origin of a 200 by
+		// 200 orthotope, and tail more bridges.
+		block int
+		tail  [][]int
+		// demolish is demolished after a first demolition at the origin.
+		demolish []int
+		// maxVisited bounds the locations searched by the second demolition.
+		maxVisited int
+		wantSplit  bool
 	}{
 		{
-			name: "single 1D",
-			fields: fields{
-				Lengths:    []int{1},
-				bridges:    map[string]bool{},
-				nonBridges: map[string]bool{"0": true},
-			},
-			want: []int{0},
-			wantO: &Orthotope{
-				Lengths:    []int{1},
-				bridges:    map[string]bool{"0": true},
-				nonBridges: map[string]bool{},
-			},
-			wantErr: false,
+			name:       "edge of full orthotope",
+			block:      200,
+			demolish:   []int{100, 0},
+			maxVisited: 20,
 		},
 		{
-			name: "double 1D",
-			fields: fields{
-				Lengths:    []int{2},
-				bridges:    map[string]bool{"1": true},
-				nonBridges: map[string]bool{"0": true},
-			},
-			want: []int{0},
-			wantO: &Orthotope{
-				Lengths: []int{2},
-				bridges: map[string]bool{
-					"0": true,
-					"1": true,
-				},
-				nonBridges: map[string]bool{},
-			},
-			wantErr: false,
+			name:       "corner of block",
+			block:      150,
+			demolish:   []int{149, 149},
+			maxVisited: 20,
 		},
 		{
-			name: "all occupied",
-			fields: fields{
-				Lengths: []int{2},
-				bridges: map[string]bool{
-					"0": true,
-					"1": true,
-				},
-				nonBridges: map[string]bool{},
-			},
-			want: []int{},
-			wantO: &Orthotope{
-				Lengths: []int{2},
-				bridges: map[string]bool{
-					"0": true,
-					"1": true,
-				},
-				nonBridges: map[string]bool{},
-			},
-			wantErr: true,
+			name:       "tail off block",
+			block:      150,
+			tail:       [][]int{{150, 70}, {151, 70}, {152, 70}, {153, 70}, {154, 70}, {155, 70}},
+			demolish:   []int{150, 70},
+			maxVisited: 20,
+			wantSplit:  true,
 		},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &Orthotope{
-				Lengths:    tt.fields.Lengths,
-				bridges:    tt.fields.bridges,
-				nonBridges: tt.fields.nonBridges,
+			o := newTestOrthotope(t, []int{200, 200}, nil, tt.tail...)
+			for x := 0; x < tt.block; x++ {
+				for y := 0; y < tt.block; y++ {
+					if err := o.Build(x, y); err != nil {
+						t.Fatalf("Orthotope.Build() error = %v", err)
+					}
+				}
 			}
-			got, err := o.BuildRandom()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Orthotope.BuildRandom() error = %v, wantErr %v", err, tt.wantErr)
+			if err := o.Demolish(0, 0); err != nil {
+				t.Fatalf("Orthotope.Demolish() error = %v", err)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Orthotope.BuildRandom() = %v, want %v", got, tt.want)
+
+			if err := o.Demolish(tt.demolish...); err != nil {
+				t.Fatalf("Orthotope.Demolish() error = %v", err)
+			}
+			var visited int
+			for _, q := range o.searches {
+				visited += len(q)
 			}
-			if !reflect.DeepEqual(o, tt.wantO) {
-				t.Errorf("Orthotope.BuildRandom() -> %+v, want %+v", *o, *tt.wantO)
+			if visited > tt.maxVisited {
+				t.Errorf("Orthotope.Demolish(%v) searched %d locations, want at most %d", tt.demolish, visited, tt.maxVisited)
 			}
+			if split := len(tt.tail) > 1 && o.root(o.index(tt.tail[1]...)) != o.root(o.index(1, 1)); split != tt.wantSplit {
+				t.Errorf("Orthotope.Demolish(%v) split tail = %v, want %v", tt.demolish, split, tt.wantSplit)
+			}
+			checkInvariants(t, o)
+			checkComponents(t, o)
 		})
 	}
 }
 
 func TestOrthotope_Built(t *testing.T) {
 	type fields struct {
-		Lengths    []int
-		bridges    map[string]bool
-		nonBridges map[string]bool
+		Lengths []int
+		built   [][]int
 	}
 	type args struct {
 		locs []int
@@ -323,15 +846,56 @@ func TestOrthotope_Built(t *testing.T) {
 		want    bool
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "built",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{1, 2}, {2, 3}},
+			},
+			args: args{
+				locs: []int{2, 3},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "not built",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{1, 2}},
+			},
+			args: args{
+				locs: []int{2, 1},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "out of bounds",
+			fields: fields{
+				Lengths: []int{3, 4},
+			},
+			args: args{
+				locs: []int{-1, 1},
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "0-D",
+			fields: fields{
+				Lengths: []int{},
+			},
+			args: args{
+				locs: []int{},
+			},
+			want:    false,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &Orthotope{
-				Lengths:    tt.fields.Lengths,
-				bridges:    tt.fields.bridges,
-				nonBridges: tt.fields.nonBridges,
-			}
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
 			got, err := o.Built(tt.args.locs...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Orthotope.Built() error = %v, wantErr %v", err, tt.wantErr)
@@ -346,9 +910,9 @@ func TestOrthotope_Built(t *testing.T) {
 
 func TestOrthotope_BridgeComplete(t *testing.T) {
 	type fields struct {
-		Lengths    []int
-		bridges    map[string]bool
-		nonBridges map[string]bool
+		Lengths []int
+		opts    []Option
+		built   [][]int
 	}
 	tests := []struct {
 		name    string
@@ -359,9 +923,7 @@ func TestOrthotope_BridgeComplete(t *testing.T) {
 		{
 			name: "empty",
 			fields: fields{
-				Lengths:    []int{3, 4},
-				bridges:    map[string]bool{},
-				nonBridges: twoD,
+				Lengths: []int{3, 4},
 			},
 			want:    false,
 			wantErr: false,
@@ -370,25 +932,11 @@ func TestOrthotope_BridgeComplete(t *testing.T) {
 			name: "disconnected bridges",
 			fields: fields{
 				Lengths: []int{3, 4},
-				bridges: map[string]bool{
-					"0-0": true,
-					"0-2": true,
-
-					"1-1": true,
-
-					"2-1": true,
-				},
-				nonBridges: map[string]bool{
-					"0-1": true,
-					"0-3": true,
-
-					"1-0": true,
-					"1-2": true,
-					"1-3": true,
-
-					"2-0": true,
-					"2-2": true,
-					"2-3": true,
+				built: [][]int{
+					{0, 0},
+					{0, 2},
+					{1, 1},
+					{2, 1},
 				},
 			},
 			want:    false,
@@ -398,38 +946,89 @@ func TestOrthotope_BridgeComplete(t *testing.T) {
 			name: "connected bridges",
 			fields: fields{
 				Lengths: []int{3, 4},
-				bridges: map[string]bool{
-					"0-0": true,
-					"0-2": true,
-
-					"1-0": true,
-					"1-1": true,
-
-					"2-1": true,
-				},
-				nonBridges: map[string]bool{
-					"0-1": true,
-					"0-3": true,
-
-					"1-2": true,
-					"1-3": true,
-
-					"2-0": true,
-					"2-2": true,
-					"2-3": true,
+				built: [][]int{
+					{0, 0},
+					{0, 2},
+					{1, 0},
+					{1, 1},
+					{2, 1},
 				},
 			},
 			want:    true,
 			wantErr: false,
 		},
+		{
+			name: "diagonal bridges",
+			fields: fields{
+				Lengths: []int{3, 3},
+				built:   [][]int{{0, 0}, {1, 1}, {2, 2}},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "diagonal bridges Moore",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithNeighborhood(Moore)},
+				built:   [][]int{{0, 0}, {1, 1}, {2, 2}},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "corner bridges edge connected",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+				opts:    []Option{WithNeighborhood(EdgeConnected)},
+				built:   [][]int{{0, 0, 0}, {1, 1, 1}},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "edge bridges edge connected",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+				opts:    []Option{WithNeighborhood(EdgeConnected)},
+				built:   [][]int{{0, 0, 0}, {1, 1, 0}},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "wrapped bridges",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 3}, {2, 3}},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "wrapped bridges periodic",
+			fields: fields{
+				Lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Periodic)},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 3}, {2, 3}},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "stencil jump",
+			fields: fields{
+				Lengths: []int{5},
+				opts:    []Option{WithNeighborhood(Stencil([]int{2}))},
+				built:   [][]int{{0}, {2}, {4}},
+			},
+			want:    true,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &Orthotope{
-				Lengths:    tt.fields.Lengths,
-				bridges:    tt.fields.bridges,
-				nonBridges: tt.fields.nonBridges,
-			}
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
 			got, err := o.BridgeComplete()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Orthotope.BridgeComplete() error = %v, wantErr %v", err, tt.wantErr)
@@ -437,15 +1036,62 @@ func TestOrthotope_BridgeComplete(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("Orthotope.BridgeComplete() = %v, want %v", got, tt.want)
 			}
+			bfs, err := o.bridgeCompleteBFS()
+			if err != nil {
+				t.Fatalf("Orthotope.bridgeCompleteBFS() error = %v", err)
+			}
+			if got != bfs {
+				t.Errorf("Orthotope.BridgeComplete() = %v, bridgeCompleteBFS() = %v", got, bfs)
+			}
+		})
+	}
+}
+
+func TestOrthotope_BridgeComplete_matchesBFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+	}{
+		{name: "1D", lengths: []int{6}},
+		{name: "2D", lengths: []int{15, 10}},
+		{name: "2D thin", lengths: []int{1, 8}},
+		{name: "2D Moore", lengths: []int{15, 10}, opts: []Option{WithNeighborhood(Moore)}},
+		{name: "3D", lengths: []int{5, 4, 3}},
+		{name: "3D edge connected", lengths: []int{5, 4, 3}, opts: []Option{WithNeighborhood(EdgeConnected)}},
+		{name: "4D", lengths: []int{3, 3, 2, 3}},
+		{name: "4D Moore", lengths: []int{3, 3, 2, 3}, opts: []Option{WithNeighborhood(Moore)}},
+		{name: "2D periodic", lengths: []int{15, 10}, opts: []Option{WithBoundaries(Open, Periodic)}},
+		{name: "3D periodic", lengths: []int{5, 4, 2}, opts: []Option{WithBoundaries(Open, Periodic, Periodic)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.lengths, tt.opts)
+			for o.nBuilt < len(o.cells) {
+				locs, err := o.BuildRandom()
+				if err != nil {
+					t.Fatalf("Orthotope.BuildRandom() error = %v", err)
+				}
+				got, err := o.BridgeComplete()
+				if err != nil {
+					t.Fatalf("Orthotope.BridgeComplete() error = %v", err)
+				}
+				want, err := o.bridgeCompleteBFS()
+				if err != nil {
+					t.Fatalf("Orthotope.bridgeCompleteBFS() error = %v", err)
+				}
+				if got != want {
+					t.Fatalf("after building %v: Orthotope.BridgeComplete() = %v, bridgeCompleteBFS() = %v", locs, got, want)
+				}
+			}
+			checkInvariants(t, o)
 		})
 	}
 }
 
 func TestOrthotope_inBound(t *testing.T) {
 	type fields struct {
-		Lengths    []int
-		bridges    map[string]bool
-		nonBridges map[string]bool
+		Lengths []int
 	}
 	type args struct {
 		locs []int
@@ -502,111 +1148,65 @@ func TestOrthotope_inBound(t *testing.T) {
 			},
 			want: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			o := &Orthotope{
-				Lengths:    tt.fields.Lengths,
-				bridges:    tt.fields.bridges,
-				nonBridges: tt.fields.nonBridges,
-			}
-			if got := o.inBound(tt.args.locs...); got != tt.want {
-				t.Errorf("Orthotope.inBound() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_key(t *testing.T) {
-	type args struct {
-		locs []int
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "empty",
-			args: args{
-				locs: []int{},
-			},
-			want: "",
-		},
 		{
-			name: "single",
-			args: args{
-				locs: []int{1},
+			name: "2D missing dimension",
+			fields: fields{
+				Lengths: []int{3, 4},
 			},
-			want: "1",
-		},
-		{
-			name: "multiple",
 			args: args{
-				locs: []int{1, 2, 5},
+				locs: []int{0},
 			},
-			want: "1-2-5",
+			want: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := key(tt.args.locs...); got != tt.want {
-				t.Errorf("key() = %v, want %v", got, tt.want)
+			o := newTestOrthotope(t, tt.fields.Lengths, nil)
+			if got := o.inBound(tt.args.locs...); got != tt.want {
+				t.Errorf("Orthotope.inBound() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_locations(t *testing.T) {
-	type args struct {
-		key string
+func TestOrthotope_index(t *testing.T) {
+	type fields struct {
+		Lengths []int
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    []int
-		wantErr bool
+		name   string
+		fields fields
+		locs   []int
+		want   int
 	}{
 		{
-			name: "empty",
-			args: args{
-				key: "",
-			},
-			wantErr: false,
-		},
-		{
-			name: "single",
-			args: args{
-				key: "1",
-			},
-			want:    []int{1},
-			wantErr: false,
+			name:   "1D",
+			fields: fields{Lengths: []int{4}},
+			locs:   []int{2},
+			want:   2,
 		},
 		{
-			name: "multiple",
-			args: args{
-				key: "1-2-5",
-			},
-			want:    []int{1, 2, 5},
-			wantErr: false,
+			name:   "2D",
+			fields: fields{Lengths: []int{3, 4}},
+			locs:   []int{1, 2},
+			want:   6,
 		},
 		{
-			name: "wrong format",
-			args: args{
-				key: "-1",
-			},
-			wantErr: true,
+			name:   "3D",
+			fields: fields{Lengths: []int{3, 4, 5}},
+			locs:   []int{1, 2, 3},
+			want:   33,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := locations(tt.args.key)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("locations() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			o := newTestOrthotope(t, tt.fields.Lengths, nil)
+			got := o.index(tt.locs...)
+			if got != tt.want {
+				t.Errorf("Orthotope.index() = %v, want %v", got, tt.want)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("locations() = %v (%v), want %v (%v)", got, got == nil, tt.want, tt.want == nil)
+			if back := o.coords(got); !reflect.DeepEqual(back, tt.locs) {
+				t.Errorf("Orthotope.coords(%d) = %v, want %v", got, back, tt.locs)
 			}
 		})
 	}
@@ -614,9 +1214,8 @@ func Test_locations(t *testing.T) {
 
 func TestOrthotope_Neighbors(t *testing.T) {
 	type fields struct {
-		Lengths    []int
-		bridges    map[string]bool
-		nonBridges map[string]bool
+		Lengths []int
+		opts    []Option
 	}
 	type args struct {
 		locs []int
@@ -629,11 +1228,9 @@ func TestOrthotope_Neighbors(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "",
+			name: "2D inner",
 			fields: fields{
-				Lengths:    []int{3, 4},
-				bridges:    twoD,
-				nonBridges: map[string]bool{},
+				Lengths: []int{3, 4},
 			},
 			args: args{
 				locs: []int{1, 2},
@@ -647,11 +1244,9 @@ func TestOrthotope_Neighbors(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "",
+			name: "3D corner",
 			fields: fields{
-				Lengths:    []int{2, 2, 2},
-				bridges:    threeD,
-				nonBridges: map[string]bool{},
+				Lengths: []int{2, 2, 2},
 			},
 			args: args{
 				locs: []int{0, 1, 1},
@@ -663,14 +1258,69 @@ func TestOrthotope_Neighbors(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "2D Moore edge",
+			fields: fields{
+				Lengths: []int{3, 4},
+				opts:    []Option{WithNeighborhood(Moore)},
+			},
+			args: args{
+				locs: []int{0, 2},
+			},
+			want: [][]int{
+				{1, 2},
+				{0, 1},
+				{0, 3},
+				{1, 1},
+				{1, 3},
+			},
+			wantErr: false,
+		},
+		{
+			name: "2D periodic corner",
+			fields: fields{
+				Lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Periodic)},
+			},
+			args: args{
+				locs: []int{0, 0},
+			},
+			want: [][]int{
+				{1, 0},
+				{0, 3},
+				{0, 1},
+			},
+			wantErr: false,
+		},
+		{
+			name: "periodic short sides",
+			fields: fields{
+				Lengths: []int{2, 2, 1},
+				opts:    []Option{WithBoundaries(Open, Periodic, Periodic)},
+			},
+			args: args{
+				locs: []int{0, 0, 0},
+			},
+			want: [][]int{
+				{1, 0, 0},
+				{0, 1, 0},
+			},
+			wantErr: false,
+		},
+		{
+			name: "out of bounds",
+			fields: fields{
+				Lengths: []int{2, 2},
+			},
+			args: args{
+				locs: []int{0, 2},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := &Orthotope{
-				Lengths:    tt.fields.Lengths,
-				bridges:    tt.fields.bridges,
-				nonBridges: tt.fields.nonBridges,
-			}
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts)
 			got, err := o.Neighbors(tt.args.locs...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Orthotope.Neighbors() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,89 @@
+package orth
+
+import "fmt"
+
+// SpanningPath returns the locations of a shortest connected path of bridges
+// from the 0 to the n-1 face of a dimension, in order. The dimension is the
+// lowest Open one that the SpanningRule counts and that is spanned, which by
+// default means from 0 to o.Lengths[0]-1 along the 1st dimension.
+// It returns an error wrapping ErrNoPath if there is no such dimension.
+//
+// If the Orthotope has a Direction other than Undirected, the path is instead
+// a shortest one following it along the 1st dimension, and there is none
+// unless DirectedComplete.
+func (o *Orthotope) SpanningPath() ([][]int, error) {
+
+	if o.directed != nil {
+		return o.directedPath()
+	}
+
+	axis := -1
+	candidates := o.rule.axes(o.periodic) & o.tally.axes()
+	for d, p := range o.periodic {
+		if !p && candidates&(1<<uint(d)) != 0 {
+			axis = d
+			break
+		}
+	}
+	if axis < 0 {
+		return nil, fmt.Errorf("no bridges connect the faces of a dimension counted by %v in %v: %w", o.rule, o.Lengths, ErrNoPath)
+	}
+	low := uint64(1) << uint(axis)
+	high := low << uint(len(o.Lengths))
+
+	// Breadth first search out of every bridge on the 0 face of a set spanning
+	// axis, recording where each location was reached from.
+	const unvisited, source = -1, -2
+	from := make([]int32, len(o.cells))
+	for i := range from {
+		from[i] = unvisited
+	}
+
+	var q []int
+	for _, c := range o.cells[:o.nBuilt] {
+		i := int(c)
+		if o.faces(i)&low == 0 || o.spans(o.components.flags(o.root(i)))&low == 0 {
+			continue
+		}
+		from[i] = source
+		q = append(q, i)
+	}
+
+	var buf []int
+	for len(q) > 0 {
+		cur := q[0]
+		q = q[1:]
+
+		if o.faces(cur)&high != 0 {
+			return o.trace(from, cur), nil
+		}
+
+		buf = o.neighbors(cur, buf[:0])
+		for _, n := range buf {
+			if o.built.get(n) && from[n] == unvisited {
+				from[n] = int32(cur)
+				q = append(q, n)
+			}
+		}
+	}
+
+	// Having a spanning set without a path is against invariant.
+	return nil, fmt.Errorf("spanning set without a path in %v: %w", o.Lengths, ErrInternalState)
+}
+
+// trace follows from back from index end to a search source and returns the
+// locations visited, source first.
+func (o *Orthotope) trace(from []int32, end int) [][]int {
+
+	var path [][]int
+	for i := end; i >= 0; i = int(from[i]) {
+		path = append(path, o.coords(i))
+	}
+
+	// Reverse so the path runs from the 0 face.
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+
+	return path
+}
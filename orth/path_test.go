@@ -0,0 +1,176 @@
+package orth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// checkPath fails t if path is not a chain of neighboring bridges from the 0
+// face to the n_1-1 face of the 1st dimension.
+func checkPath(t *testing.T, o *Orthotope, path [][]int) {
+	t.Helper()
+
+	if len(path) == 0 {
+		t.Fatalf("empty path")
+	}
+	if path[0][0] != 0 || path[len(path)-1][0] != o.Lengths[0]-1 {
+		t.Fatalf("path %v does not run from 0 to %d", path, o.Lengths[0]-1)
+	}
+	for k, locs := range path {
+		if b, err := o.Built(locs...); err != nil || !b {
+			t.Fatalf("path location %v built = %v, error = %v", locs, b, err)
+		}
+		if k == 0 {
+			continue
+		}
+		neighbors, err := o.Neighbors(path[k-1]...)
+		if err != nil {
+			t.Fatalf("Orthotope.Neighbors(%v) error = %v", path[k-1], err)
+		}
+		adjacent := false
+		for _, n := range neighbors {
+			if reflect.DeepEqual(n, locs) {
+				adjacent = true
+			}
+		}
+		if !adjacent {
+			t.Fatalf("path steps from %v to non-neighbor %v", path[k-1], locs)
+		}
+	}
+}
+
+func TestOrthotope_SpanningPath(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		built   [][]int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    [][]int
+		wantErr error
+	}{
+		{
+			name: "empty",
+			fields: fields{
+				Lengths: []int{3, 4},
+			},
+			wantErr: ErrNoPath,
+		},
+		{
+			name: "disconnected bridges",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{0, 0}, {0, 2}, {1, 1}, {2, 1}},
+			},
+			wantErr: ErrNoPath,
+		},
+		{
+			name: "connected bridges",
+			fields: fields{
+				Lengths: []int{3, 4},
+				built:   [][]int{{0, 0}, {0, 2}, {1, 0}, {1, 1}, {2, 1}},
+			},
+			want: [][]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+		},
+		{
+			name: "shortcut",
+			fields: fields{
+				Lengths: []int{4, 3},
+				built: [][]int{
+					{0, 0}, {1, 0}, {1, 1}, {1, 2}, {2, 2}, {3, 2},
+					{0, 1}, {2, 1}, {3, 1},
+				},
+			},
+			want: [][]int{{0, 1}, {1, 1}, {2, 1}, {3, 1}},
+		},
+		{
+			name: "1D",
+			fields: fields{
+				Lengths: []int{3},
+				built:   [][]int{{2}, {1}, {0}},
+			},
+			want: [][]int{{0}, {1}, {2}},
+		},
+		{
+			name: "single width",
+			fields: fields{
+				Lengths: []int{1, 3},
+				built:   [][]int{{0, 2}},
+			},
+			want: [][]int{{0, 2}},
+		},
+		{
+			name: "Moore diagonal",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithNeighborhood(Moore)},
+				built:   [][]int{{0, 0}, {1, 1}, {2, 2}},
+			},
+			want: [][]int{{0, 0}, {1, 1}, {2, 2}},
+		},
+		{
+			name: "2nd axis rule",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithSpanningRule(SpanAxis(1))},
+				built:   [][]int{{0, 0}, {1, 0}, {2, 0}, {1, 1}, {1, 2}},
+			},
+			want: [][]int{{1, 0}, {1, 1}, {1, 2}},
+		},
+		{
+			name: "wrapping only",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithBoundaries(Open, Periodic), WithSpanningRule(SpanWrapping)},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			wantErr: ErrNoPath,
+		},
+		{
+			name: "3D",
+			fields: fields{
+				Lengths: []int{3, 2, 2},
+				built:   [][]int{{0, 1, 1}, {1, 1, 1}, {1, 0, 1}, {2, 0, 1}, {2, 0, 0}},
+			},
+			want: [][]int{{0, 1, 1}, {1, 1, 1}, {1, 0, 1}, {2, 0, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
+			got, err := o.SpanningPath()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Orthotope.SpanningPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.SpanningPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrthotope_SpanningPath_random(t *testing.T) {
+	for _, lengths := range [][]int{{15, 10}, {6, 5, 4}} {
+		o := newTestOrthotope(t, lengths, nil)
+		for {
+			if _, err := o.BuildRandom(); err != nil {
+				t.Fatalf("Orthotope.BuildRandom() error = %v", err)
+			}
+			if complete, _ := o.BridgeComplete(); complete {
+				break
+			}
+			if _, err := o.SpanningPath(); !errors.Is(err, ErrNoPath) {
+				t.Fatalf("Orthotope.SpanningPath() error = %v before completion, want %v", err, ErrNoPath)
+			}
+		}
+		path, err := o.SpanningPath()
+		if err != nil {
+			t.Fatalf("Orthotope.SpanningPath() error = %v", err)
+		}
+		checkPath(t, o, path)
+	}
+}
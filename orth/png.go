@@ -0,0 +1,104 @@
+package orth
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// PNGOptions configures WritePNG. Zero fields take their defaults.
+type PNGOptions struct {
+	// CellSize is the side of a cell in pixels. The default is 1.
+	CellSize int
+	// Empty, Bridge and Spanning color unoccupied cells, bridges and bridges of
+	// clusters completing the bridge. The defaults are light gray, steel blue
+	// and red.
+	Empty    color.Color
+	Bridge   color.Color
+	Spanning color.Color
+	// Slice chooses the cross-section drawn, the first one if several are.
+	Slice []SliceOption
+}
+
+// WritePNG writes the cross-section chosen by opts as a PNG image with one
+// square of opts.CellSize pixels per cell, oriented as WriteSVG draws it: the
+// 1st plane dimension left to right and the 2nd bottom to top.
+func (o *Orthotope) WritePNG(w io.Writer, opts PNGOptions) error {
+
+	size, err := opts.cellSize()
+	if err != nil {
+		return err
+	}
+	v, err := o.newView(opts.Slice)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, v.raster(size, opts.palette(), false))
+}
+
+// cellSize returns opts.CellSize or its default.
+func (opts PNGOptions) cellSize() (int, error) {
+
+	switch {
+	case opts.CellSize < 0:
+		return 0, fmt.Errorf("cell size %d: %w", opts.CellSize, ErrInvalidOption)
+	case opts.CellSize == 0:
+		return 1, nil
+	default:
+		return opts.CellSize, nil
+	}
+}
+
+// palette returns the empty, bridge and spanning colors of opts or their
+// defaults.
+func (opts PNGOptions) palette() color.Palette {
+	return color.Palette{
+		orColor(opts.Empty, color.RGBA{R: 0xf2, G: 0xf2, B: 0xf2, A: 0xff}),
+		orColor(opts.Bridge, color.RGBA{R: 0x46, G: 0x82, B: 0xb4, A: 0xff}),
+		orColor(opts.Spanning, color.RGBA{R: 0xc0, G: 0x39, B: 0x2b, A: 0xff}),
+	}
+}
+
+// raster draws the view with one square of size pixels per cell, colored by
+// palette: empty, bridge, spanning cluster and, if path, spanning path.
+func (v *view) raster(size int, palette color.Palette, path bool) *image.Paletted {
+
+	img := image.NewPaletted(image.Rect(0, 0, v.width()*size, v.height()*size), palette)
+	for cy := 0; cy < v.height(); cy++ {
+		for cx := 0; cx < v.width(); cx++ {
+			label, spanning, onPath := v.cell(cx, cy)
+			var k uint8
+			switch {
+			case path && onPath:
+				k = 3
+			case spanning:
+				k = 2
+			case label != 0:
+				k = 1
+			default:
+				continue
+			}
+			for py := cy * size; py < (cy+1)*size; py++ {
+				row := img.Pix[py*img.Stride:]
+				for px := cx * size; px < (cx+1)*size; px++ {
+					row[px] = k
+				}
+			}
+		}
+	}
+
+	return img
+}
+
+// orColor returns c, or def if c is nil.
+func orColor(c, def color.Color) color.Color {
+
+	if c == nil {
+		return def
+	}
+
+	return c
+}
@@ -0,0 +1,136 @@
+package orth
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestOrthotope_WritePNG(t *testing.T) {
+
+	empty := color.RGBA{A: 0xff}
+	bridge := color.RGBA{G: 0xff, A: 0xff}
+	spanning := color.RGBA{R: 0xff, A: 0xff}
+	palette := PNGOptions{Empty: empty, Bridge: bridge, Spanning: spanning}
+
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		opts    PNGOptions
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "one pixel per cell",
+			fields: fields{
+				Lengths: []int{3, 2},
+				built:   [][]int{{0, 0}, {1, 0}, {2, 0}, {1, 1}, {2, 1}},
+			},
+			opts: palette,
+			// . empty, b bridge, s spanning, by pixel row.
+			want: []string{".ss", "sss"},
+		},
+		{
+			name: "not spanning",
+			fields: fields{
+				Lengths: []int{3, 2},
+				built:   [][]int{{0, 0}, {2, 1}},
+			},
+			opts: palette,
+			want: []string{"..b", "b.."},
+		},
+		{
+			name: "cell size",
+			fields: fields{
+				Lengths: []int{2, 2},
+				built:   [][]int{{1, 0}},
+			},
+			opts: PNGOptions{CellSize: 2, Empty: empty, Bridge: bridge, Spanning: spanning},
+			want: []string{"....", "....", "..bb", "..bb"},
+		},
+		{
+			name: "slice",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+				built:   [][]int{{0, 1, 1}, {0, 0, 0}},
+			},
+			opts: PNGOptions{Empty: empty, Bridge: bridge, Spanning: spanning, Slice: []SliceOption{WithSlice(0, 0, 1)}},
+			want: []string{"b.", ".."},
+		},
+		{
+			name:    "negative cell size",
+			fields:  fields{Lengths: []int{2, 2}},
+			opts:    PNGOptions{CellSize: -1},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "1D",
+			fields:  fields{Lengths: []int{2}},
+			wantErr: ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			var buf bytes.Buffer
+			err := o.WritePNG(&buf, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.WritePNG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("png.Decode() error = %v", err)
+			}
+			names := map[color.RGBA]byte{empty: '.', bridge: 'b', spanning: 's'}
+			var got []string
+			for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+				var row []byte
+				for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+					row = append(row, names[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)])
+				}
+				got = append(got, string(row))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Orthotope.WritePNG() = %q, want %q", got, tt.want)
+			}
+			for k := range got {
+				if got[k] != tt.want[k] {
+					t.Fatalf("Orthotope.WritePNG() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOrthotope_WritePNG_large(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("large image")
+	}
+
+	o := newTestOrthotope(t, []int{1000, 1000}, nil)
+	if err := o.FillBernoulli(0.6, nil); err != nil {
+		t.Fatalf("Orthotope.FillBernoulli() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := o.WritePNG(&buf, PNGOptions{}); err != nil {
+		t.Fatalf("Orthotope.WritePNG() error = %v", err)
+	}
+	cfg, err := png.DecodeConfig(&buf)
+	if err != nil {
+		t.Fatalf("png.DecodeConfig() error = %v", err)
+	}
+	if cfg.Width != 1000 || cfg.Height != 1000 {
+		t.Errorf("Orthotope.WritePNG() is %dx%d, want 1000x1000", cfg.Width, cfg.Height)
+	}
+}
@@ -0,0 +1,159 @@
+package orth
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// view is one cross-section of an Orthotope prepared for drawing, with its
+// bridges labeled by cluster, the clusters completing the bridge noted and the
+// spanning path marked.
+type view struct {
+	o        *Orthotope
+	x, y     int
+	at       []int
+	labels   *Labeling
+	spanning []bool
+	path     map[int]bool
+}
+
+// newView returns the first cross-section chosen by opts.
+func (o *Orthotope) newView(opts []SliceOption) (*view, error) {
+
+	c, err := o.newSliceConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var at []int
+	c.eachSlice(&o.lattice, func(a []int) {
+		if at == nil {
+			at = a
+		}
+	})
+	if at == nil {
+		return nil, fmt.Errorf("no cross-section in range: %w", ErrOutOfBounds)
+	}
+
+	v := &view{o: o, x: c.x, y: c.y, at: at, labels: o.Clusters(), path: map[int]bool{}}
+	path, err := o.SpanningPath()
+	if err != nil && !errors.Is(err, ErrNoPath) {
+		return nil, err
+	}
+	for _, l := range path {
+		v.path[o.index(l...)] = true
+	}
+	v.spanning = v.completing()
+
+	return v, nil
+}
+
+// completing returns, for each cluster by label-1, whether it completes the
+// bridge on its own: it meets the SpanningRule or, if the Orthotope is
+// directed, holds the directed spanning path.
+func (v *view) completing() []bool {
+
+	spanning := make([]bool, len(v.labels.Clusters))
+	if v.o.directed != nil {
+		for i := range v.path {
+			if label := v.labels.labels[i]; label > 0 {
+				spanning[label-1] = true
+			}
+		}
+		return spanning
+	}
+
+	all := uint64(1)<<uint(len(v.o.Lengths)) - 1
+	for k, c := range v.labels.Clusters {
+		var mask uint64
+		for _, d := range c.Spans {
+			mask |= 1 << uint(d)
+		}
+		spanning[k] = v.o.rule.complete(v.o.periodic, mask, mask == all)
+	}
+
+	return spanning
+}
+
+// width and height are the number of cells across and down the view.
+func (v *view) width() int  { return v.o.Lengths[v.x] }
+func (v *view) height() int { return v.o.Lengths[v.y] }
+
+// cell returns the cluster label of the cell at column cx and row cy, or 0 if
+// it holds no bridge, whether that cluster completes the bridge and whether
+// the cell is on the spanning path. Rows count down from the top of the
+// drawing, so that, as in the README, location 0 of the 2nd plane dimension
+// is the bottom row.
+func (v *view) cell(cx, cy int) (int, bool, bool) {
+
+	v.at[v.x], v.at[v.y] = cx, v.height()-1-cy
+	i := v.o.index(v.at...)
+	label := int(v.labels.labels[i])
+	if label == 0 {
+		return 0, false, false
+	}
+
+	return label, v.spanning[label-1], v.path[i]
+}
+
+// label returns the view's location with the plane dimensions shown as *.
+func (v *view) label() string {
+	return sliceConfig{x: v.x, y: v.y}.label(v.at)
+}
+
+// clusterColor returns a color for cluster label, spreading successive labels
+// around the hue circle by the golden angle so neighbors rarely look alike.
+// Clusters completing the bridge are drawn darker and fully saturated.
+func clusterColor(label int, spanning bool) color.RGBA {
+
+	hue := math.Mod(float64(label)*137.508, 360)
+	s, l := 0.45, 0.7
+	if spanning {
+		s, l = 0.9, 0.4
+	}
+
+	return hsl(hue, s, l)
+}
+
+// hsl converts a hue in degrees, saturation and lightness in [0, 1] to RGB.
+func hsl(h, s, l float64) color.RGBA {
+
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = c, x
+	case hp < 2:
+		r, g = x, c
+	case hp < 3:
+		g, b = c, x
+	case hp < 4:
+		g, b = x, c
+	case hp < 5:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := l - c/2
+	to8 := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+
+	return color.RGBA{R: to8(r), G: to8(g), B: to8(b), A: 255}
+}
+
+// tickStep returns a spacing of 1, 2 or 5 times a power of 10 giving at most
+// about 10 ticks along n cells.
+func tickStep(n int) int {
+
+	for step := 1; ; step *= 10 {
+		for _, m := range []int{1, 2, 5} {
+			if n <= 10*m*step {
+				return m * step
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package orth
+
+import (
+	"image/color"
+	"testing"
+)
+
+func Test_tickStep(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{n: 1, want: 1},
+		{n: 10, want: 1},
+		{n: 11, want: 2},
+		{n: 50, want: 5},
+		{n: 51, want: 10},
+		{n: 4000, want: 500},
+	}
+	for _, tt := range tests {
+		if got := tickStep(tt.n); got != tt.want {
+			t.Errorf("tickStep(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func Test_hsl(t *testing.T) {
+	tests := []struct {
+		name    string
+		h, s, l float64
+		want    color.RGBA
+	}{
+		{name: "red", h: 0, s: 1, l: 0.5, want: color.RGBA{R: 255, A: 255}},
+		{name: "green", h: 120, s: 1, l: 0.5, want: color.RGBA{G: 255, A: 255}},
+		{name: "blue", h: 240, s: 1, l: 0.5, want: color.RGBA{B: 255, A: 255}},
+		{name: "gray", h: 90, s: 0, l: 0.5, want: color.RGBA{R: 128, G: 128, B: 128, A: 255}},
+		{name: "white", h: 300, s: 1, l: 1, want: color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hsl(tt.h, tt.s, tt.l); got != tt.want {
+				t.Errorf("hsl() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_clusterColor(t *testing.T) {
+
+	seen := map[color.RGBA]bool{}
+	for label := 1; label <= 20; label++ {
+		c := clusterColor(label, false)
+		if seen[c] {
+			t.Errorf("clusterColor(%d) = %v repeats", label, c)
+		}
+		seen[c] = true
+		if s := clusterColor(label, true); s == c {
+			t.Errorf("clusterColor(%d) spanning matches non-spanning", label)
+		}
+	}
+}
+
+func Test_view_cell(t *testing.T) {
+
+	column := [][]int{{2, 0}, {2, 1}, {2, 2}}
+	row := [][]int{{0, 1}, {1, 1}, {2, 1}, {3, 1}, {4, 1}}
+	tests := []struct {
+		name         string
+		opts         []Option
+		built        [][]int
+		cx, cy       int
+		wantLabel    int
+		wantSpanning bool
+	}{
+		{
+			name:      "empty",
+			built:     column,
+			cx:        0,
+			cy:        0,
+			wantLabel: 0,
+		},
+		{
+			name:         "spans axis 1, bridge incomplete",
+			built:        column,
+			cx:           2,
+			cy:           1,
+			wantLabel:    1,
+			wantSpanning: false,
+		},
+		{
+			name:         "spans axis 1 by rule",
+			opts:         []Option{WithSpanningRule(SpanAxis(1))},
+			built:        column,
+			cx:           2,
+			cy:           1,
+			wantLabel:    1,
+			wantSpanning: true,
+		},
+		{
+			name:         "spans axis 0",
+			built:        row,
+			cx:           4,
+			cy:           1,
+			wantLabel:    1,
+			wantSpanning: true,
+		},
+		{
+			name:         "spans one of all",
+			opts:         []Option{WithSpanningRule(SpanAll)},
+			built:        row,
+			cx:           4,
+			cy:           1,
+			wantLabel:    1,
+			wantSpanning: false,
+		},
+		{
+			name:         "directed path",
+			opts:         []Option{WithDirection(Forward)},
+			built:        row,
+			cx:           0,
+			cy:           1,
+			wantLabel:    1,
+			wantSpanning: true,
+		},
+		{
+			name:         "directed, no path",
+			opts:         []Option{WithDirection(Forward)},
+			built:        column,
+			cx:           2,
+			cy:           2,
+			wantLabel:    1,
+			wantSpanning: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, []int{5, 3}, tt.opts, tt.built...)
+			v, err := o.newView(nil)
+			if err != nil {
+				t.Fatalf("Orthotope.newView() error = %v", err)
+			}
+			label, spanning, _ := v.cell(tt.cx, tt.cy)
+			if label != tt.wantLabel || spanning != tt.wantSpanning {
+				t.Errorf("view.cell(%d, %d) = %d, %v, want %d, %v", tt.cx, tt.cy, label, spanning, tt.wantLabel, tt.wantSpanning)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package orth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SliceOption configures which 2D cross-sections of an Orthotope are drawn.
+type SliceOption func(*sliceConfig)
+
+type sliceConfig struct {
+	x, y int
+	// ranges maps a dimension outside the plane to the half-open range of
+	// locations drawn along it.
+	ranges map[int][2]int
+	// at, if set, fixes the location along every dimension outside the plane.
+	at []int
+}
+
+// WithPlane sets the dimensions running across, x, and down, y, each
+// cross-section. The default is WithPlane(0, 1).
+func WithPlane(x, y int) SliceOption {
+	return func(c *sliceConfig) {
+		c.x, c.y = x, y
+	}
+}
+
+// WithSliceRange limits the cross-sections drawn to those at locations from
+// to to-1 along dimension d, which must lie outside the plane. The default is
+// every location.
+func WithSliceRange(d, from, to int) SliceOption {
+	return func(c *sliceConfig) {
+		c.ranges[d] = [2]int{from, to}
+	}
+}
+
+// WithSlice limits the cross-sections drawn to the one through the location
+// at, whose entries along the plane dimensions are ignored.
+func WithSlice(at ...int) SliceOption {
+	return func(c *sliceConfig) {
+		c.at = append([]int{}, at...)
+	}
+}
+
+// newSliceConfig returns the slice configuration set by opts, checked against
+// the dimensions of l.
+func (l *lattice) newSliceConfig(opts []SliceOption) (sliceConfig, error) {
+
+	c := sliceConfig{x: 0, y: 1, ranges: map[int][2]int{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	n := len(l.Lengths)
+	if c.x < 0 || c.x >= n || c.y < 0 || c.y >= n || c.x == c.y {
+		return c, fmt.Errorf("plane %d, %d of %d dimensions: %w", c.x, c.y, n, ErrInvalidOption)
+	}
+	if c.at != nil {
+		if len(c.at) != n {
+			return c, fmt.Errorf("slice through %v in %d dimensions: %w", c.at, n, ErrOutOfBounds)
+		}
+		for d, loc := range c.at {
+			if d != c.x && d != c.y {
+				c.ranges[d] = [2]int{loc, loc + 1}
+			}
+		}
+	}
+	for d, r := range c.ranges {
+		if d < 0 || d >= n || d == c.x || d == c.y {
+			return c, fmt.Errorf("range along dimension %d outside plane %d, %d: %w", d, c.x, c.y, ErrInvalidOption)
+		}
+		if r[0] < 0 || r[1] > l.Lengths[d] || r[0] > r[1] {
+			return c, fmt.Errorf("range %d to %d along dimension %d of length %d: %w", r[0], r[1], d, l.Lengths[d], ErrOutOfBounds)
+		}
+	}
+
+	return c, nil
+}
+
+// span returns the half-open range of locations drawn along dimension d.
+func (c sliceConfig) span(l *lattice, d int) (int, int) {
+
+	if r, ok := c.ranges[d]; ok {
+		return r[0], r[1]
+	}
+
+	return 0, l.Lengths[d]
+}
+
+// eachSlice calls f with the location of the first cell of each
+// cross-section set by c, in row-major order of the dimensions outside the
+// plane.
+func (c sliceConfig) eachSlice(l *lattice, f func(at []int)) {
+
+	at := make([]int, len(l.Lengths))
+	for d := range at {
+		at[d], _ = c.span(l, d)
+	}
+	at[c.x], at[c.y] = 0, 0
+	for d := range at {
+		if from, to := c.span(l, d); d != c.x && d != c.y && from == to {
+			return
+		}
+	}
+
+	for {
+		f(append([]int{}, at...))
+
+		// Advance the last dimension outside the plane fastest.
+		d := len(at) - 1
+		for ; d >= 0; d-- {
+			if d == c.x || d == c.y {
+				continue
+			}
+			from, to := c.span(l, d)
+			if at[d]++; at[d] < to {
+				break
+			}
+			at[d] = from
+		}
+		if d < 0 {
+			return
+		}
+	}
+}
+
+// label returns at with the plane dimensions x and y shown as *.
+func (c sliceConfig) label(at []int) string {
+
+	parts := make([]string, len(at))
+	for d, loc := range at {
+		parts[d] = fmt.Sprint(loc)
+		if d == c.x || d == c.y {
+			parts[d] = "*"
+		}
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// Slices draws the 2D cross-sections of the Orthotope chosen by opts, each
+// labeled with its location and the plane dimensions shown as *, and drawn as
+// String draws a 2D Orthotope. It needs at least 2 dimensions.
+func (o *Orthotope) Slices(opts ...SliceOption) (string, error) {
+
+	c, err := o.newSliceConfig(opts)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	c.eachSlice(&o.lattice, func(at []int) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(c.label(at) + "\n")
+		b.WriteString(o.plane(c.x, c.y, at))
+	})
+
+	return b.String(), nil
+}
+
+// plane draws the cross-section through at across dimension x and down
+// dimension y.
+func (o *Orthotope) plane(x, y int, at []int) string {
+
+	locs := append([]int{}, at...)
+	var b strings.Builder
+	for locs[y] = 0; locs[y] < o.Lengths[y]; locs[y]++ {
+		for locs[x] = 0; locs[x] < o.Lengths[x]; locs[x]++ {
+			s := "."
+			if o.built.get(o.index(locs...)) {
+				s = "B"
+			}
+			b.WriteString(" " + s)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
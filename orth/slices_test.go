@@ -0,0 +1,166 @@
+package orth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrthotope_Slices(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		opts    []SliceOption
+		want    string
+		wantErr error
+	}{
+		{
+			name: "2D",
+			fields: fields{
+				Lengths: []int{3, 2},
+				built:   [][]int{{0, 0}, {2, 1}},
+			},
+			want: "[*, *]\n" +
+				" B . .\n" +
+				" . . B\n",
+		},
+		{
+			name: "3D",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+				built:   [][]int{{0, 0, 0}, {1, 1, 1}},
+			},
+			want: "[*, *, 0]\n" +
+				" B .\n" +
+				" . .\n" +
+				"\n" +
+				"[*, *, 1]\n" +
+				" . .\n" +
+				" . B\n",
+		},
+		{
+			name: "3D plane",
+			fields: fields{
+				Lengths: []int{2, 2, 3},
+				built:   [][]int{{0, 1, 2}},
+			},
+			opts: []SliceOption{WithPlane(2, 0)},
+			want: "[*, 0, *]\n" +
+				" . . .\n" +
+				" . . .\n" +
+				"\n" +
+				"[*, 1, *]\n" +
+				" . . B\n" +
+				" . . .\n",
+		},
+		{
+			name: "4D range",
+			fields: fields{
+				Lengths: []int{2, 1, 3, 2},
+				built:   [][]int{{1, 0, 1, 1}, {0, 0, 2, 0}},
+			},
+			opts: []SliceOption{WithSliceRange(2, 1, 3)},
+			want: "[*, *, 1, 0]\n" +
+				" . .\n" +
+				"\n" +
+				"[*, *, 1, 1]\n" +
+				" . B\n" +
+				"\n" +
+				"[*, *, 2, 0]\n" +
+				" B .\n" +
+				"\n" +
+				"[*, *, 2, 1]\n" +
+				" . .\n",
+		},
+		{
+			name: "empty range",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+			},
+			opts: []SliceOption{WithSliceRange(2, 1, 1)},
+			want: "",
+		},
+		{
+			name: "1D",
+			fields: fields{
+				Lengths: []int{3},
+			},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "same axis twice",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+			},
+			opts:    []SliceOption{WithPlane(1, 1)},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "range in plane",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+			},
+			opts:    []SliceOption{WithSliceRange(0, 0, 1)},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name: "range out of bounds",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+			},
+			opts:    []SliceOption{WithSliceRange(2, 1, 3)},
+			wantErr: ErrOutOfBounds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			got, err := o.Slices(tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.Slices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Orthotope.Slices() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrthotope_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		built   [][]int
+		want    string
+	}{
+		{
+			name:    "1D",
+			lengths: []int{3},
+			built:   [][]int{{1}},
+			want:    " . B .",
+		},
+		{
+			name:    "2D",
+			lengths: []int{3, 2},
+			built:   [][]int{{0, 0}, {2, 1}},
+			want:    " B . .\n . . B\n",
+		},
+		{
+			name:    "3D",
+			lengths: []int{1, 1, 2},
+			built:   [][]int{{0, 0, 1}},
+			want:    "[*, *, 0]\n .\n\n[*, *, 1]\n B\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.lengths, nil, tt.built...)
+			if got := o.String(); got != tt.want {
+				t.Errorf("Orthotope.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
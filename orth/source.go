@@ -0,0 +1,27 @@
+package orth
+
+import "math/rand"
+
+// Source supplies the random choices of an Orthotope. *rand.Rand satisfies it,
+// so rand.New(rand.NewSource(seed)) gives a reproducible run.
+type Source interface {
+	// Intn returns a uniformly random int in [0, n).
+	Intn(n int) int
+}
+
+// globalSource draws from the math/rand top-level functions.
+type globalSource struct{}
+
+func (globalSource) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+// uniform returns a uniformly random float64 in [0, 1) drawn from src, built
+// from 53 random bits so it works with any Source.
+func uniform(src Source) float64 {
+
+	hi := src.Intn(1 << 26)
+	lo := src.Intn(1 << 27)
+
+	return (float64(hi)*(1<<27) + float64(lo)) / (1 << 53)
+}
@@ -0,0 +1,214 @@
+package orth
+
+import "fmt"
+
+// SpanningRule defines when the bridge is complete, in terms of the dimensions
+// a single connected set of bridges spans. An Open dimension is spanned by
+// bridges touching both its 0 and n-1 faces, and a Periodic one by bridges
+// wrapping all the way around it.
+type SpanningRule struct {
+	kind spanKind
+	axis int
+}
+
+type spanKind int
+
+const (
+	spanAxis spanKind = iota
+	spanAny
+	spanAll
+	spanWrapping
+)
+
+var (
+	// SpanAny completes once any dimension is spanned.
+	SpanAny = SpanningRule{kind: spanAny}
+
+	// SpanAll completes once one set of bridges spans every dimension at once.
+	SpanAll = SpanningRule{kind: spanAll}
+
+	// SpanWrapping completes once bridges wrap around any Periodic dimension.
+	SpanWrapping = SpanningRule{kind: spanWrapping}
+)
+
+// SpanAxis completes once dimension d, counted from 0, is spanned. The
+// default is SpanAxis(0): a path from 0 to n_1-1 along the 1st dimension.
+func SpanAxis(d int) SpanningRule {
+	return SpanningRule{kind: spanAxis, axis: d}
+}
+
+func (r SpanningRule) String() string {
+
+	switch r.kind {
+	case spanAxis:
+		return fmt.Sprintf("axis %d", r.axis)
+	case spanAny:
+		return "any axis"
+	case spanAll:
+		return "all axes"
+	case spanWrapping:
+		return "wrapping"
+	default:
+		return "unknown"
+	}
+}
+
+// validate returns an error if r cannot apply to dimensions with the given
+// periodic boundaries.
+func (r SpanningRule) validate(periodic []bool) error {
+
+	switch r.kind {
+	case spanAxis:
+		// A 0 dimensional orthotope has nothing to span and is never complete.
+		if len(periodic) > 0 && (r.axis < 0 || r.axis >= len(periodic)) {
+			return fmt.Errorf("axis %d of %d dimensions: %w", r.axis, len(periodic), ErrInvalidOption)
+		}
+	case spanAny, spanAll:
+	case spanWrapping:
+		for _, p := range periodic {
+			if p {
+				return nil
+			}
+		}
+		return fmt.Errorf("no %v dimension to wrap around: %w", Periodic, ErrInvalidOption)
+	default:
+		return fmt.Errorf("unknown kind %d: %w", r.kind, ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// axes returns a bit for each dimension r counts towards completion.
+func (r SpanningRule) axes(periodic []bool) uint64 {
+
+	switch r.kind {
+	case spanAxis:
+		return 1 << uint(r.axis)
+	case spanWrapping:
+		var mask uint64
+		for d, p := range periodic {
+			if p {
+				mask |= 1 << uint(d)
+			}
+		}
+		return mask
+	default:
+		return 1<<uint(len(periodic)) - 1
+	}
+}
+
+// complete returns whether r is met when the dimensions in spanned are each
+// spanned by some set of bridges and, if all, one set spans them all.
+func (r SpanningRule) complete(periodic []bool, spanned uint64, all bool) bool {
+
+	if len(periodic) == 0 {
+		return false
+	}
+	if r.kind == spanAll {
+		return all
+	}
+
+	return spanned&r.axes(periodic) != 0
+}
+
+// BridgeComplete returns true if the bridges meet the Orthotope's
+// SpanningRule. By default that is a connected path of bridges from 0 to
+// o.Lengths[0]-1 along the 1st dimension. If the Orthotope has a Direction
+// other than Undirected, it is DirectedComplete instead.
+func (o *Orthotope) BridgeComplete() (bool, error) {
+
+	if o.directed != nil {
+		return o.DirectedComplete()
+	}
+
+	return o.rule.complete(o.periodic, o.tally.axes(), o.tally.all > 0), nil
+}
+
+// SpanningAxes returns, in increasing order, the dimensions spanned by some
+// set of bridges, whatever the Orthotope's SpanningRule.
+func (o *Orthotope) SpanningAxes() []int {
+	return o.tally.list()
+}
+
+// count adds delta to the tally of spanned dimensions for the set rooted at r.
+func (o *Orthotope) count(r int, delta int) {
+	o.tally.add(o.spans(o.components.flags(r)), delta)
+}
+
+// bridgeCompleteBFS answers BridgeComplete by searching every bridge from
+// scratch. It is kept as a reference to check components against.
+func (o *Orthotope) bridgeCompleteBFS() (bool, error) {
+
+	spanned, all, err := o.spannedBFS()
+	if err != nil {
+		return false, err
+	}
+
+	return o.rule.complete(o.periodic, spanned, all), nil
+}
+
+// spannedBFS returns a bit for each dimension spanned by some connected set of
+// bridges, and whether one set spans all of them, by searching every bridge.
+// Each bridge reached is given a position that does not wrap around periodic
+// dimensions; reaching one again at a different position closes a loop around
+// the torus.
+func (o *Orthotope) spannedBFS() (uint64, bool, error) {
+
+	var spanned uint64
+	all := false
+	dims := uint64(1)<<uint(len(o.Lengths)) - 1
+
+	position := map[int][]int{}
+	for _, c := range o.cells[:o.nBuilt] {
+		start := int(c)
+		if _, ok := position[start]; ok {
+			continue
+		}
+
+		// BFS
+		var flags uint64
+		position[start] = make([]int, len(o.Lengths))
+		q := []int{start}
+		for len(q) > 0 {
+			cur := q[0]
+			q = q[1:]
+
+			if !o.built.get(cur) {
+				return 0, false, fmt.Errorf("location %v reached but not built: %w", o.coords(cur), ErrInternalState)
+			}
+			flags |= o.faces(cur)
+
+			// Place neighbors relative to cur and add new ones to queue
+			locs := o.coords(cur)
+			for _, offset := range o.offsets {
+				n, ok := o.step(cur, locs, offset)
+				if !ok || !o.built.get(n) {
+					continue
+				}
+				want := make([]int, len(offset))
+				for d := range offset {
+					want[d] = position[cur][d] + offset[d]
+				}
+				got, seen := position[n]
+				if !seen {
+					position[n] = want
+					q = append(q, n)
+					continue
+				}
+				for d := range want {
+					if got[d] != want[d] {
+						flags |= 1 << (2*uint(len(o.Lengths)) + uint(d))
+					}
+				}
+			}
+		}
+
+		mask := o.spans(flags)
+		spanned |= mask
+		if mask == dims {
+			all = true
+		}
+	}
+
+	return spanned, all, nil
+}
@@ -0,0 +1,199 @@
+package orth
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestOrthotope_SpanningAxes(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		opts    []Option
+		built   [][]int
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		want         []int
+		wantComplete bool
+	}{
+		{
+			name: "empty",
+			fields: fields{
+				Lengths: []int{3, 3},
+			},
+			want:         []int{},
+			wantComplete: false,
+		},
+		{
+			name: "1st axis",
+			fields: fields{
+				Lengths: []int{3, 3},
+				built:   [][]int{{0, 1}, {1, 1}, {2, 1}},
+			},
+			want:         []int{0},
+			wantComplete: true,
+		},
+		{
+			name: "2nd axis only",
+			fields: fields{
+				Lengths: []int{3, 3},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			want:         []int{1},
+			wantComplete: false,
+		},
+		{
+			name: "2nd axis rule",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithSpanningRule(SpanAxis(1))},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			want:         []int{1},
+			wantComplete: true,
+		},
+		{
+			name: "any axis",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithSpanningRule(SpanAny)},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			want:         []int{1},
+			wantComplete: true,
+		},
+		{
+			name: "all axes by separate sets",
+			fields: fields{
+				Lengths: []int{4, 4},
+				opts:    []Option{WithSpanningRule(SpanAll)},
+				built: [][]int{
+					{0, 0}, {1, 0}, {2, 0}, {3, 0},
+					{3, 2}, {3, 3}, {2, 2}, {1, 2}, {1, 3},
+				},
+			},
+			want:         []int{0},
+			wantComplete: false,
+		},
+		{
+			name: "all axes by one set",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithSpanningRule(SpanAll)},
+				built:   [][]int{{0, 1}, {1, 1}, {2, 1}, {1, 0}, {1, 2}},
+			},
+			want:         []int{0, 1},
+			wantComplete: true,
+		},
+		{
+			name: "periodic axis touching both faces",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithBoundaries(Open, Periodic), WithSpanningRule(SpanAny)},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			want:         []int{1},
+			wantComplete: true,
+		},
+		{
+			name: "wrapping",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithBoundaries(Open, Periodic), WithSpanningRule(SpanWrapping)},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			want:         []int{1},
+			wantComplete: true,
+		},
+		{
+			name: "not wrapping",
+			fields: fields{
+				Lengths: []int{3, 4},
+				opts:    []Option{WithBoundaries(Open, Periodic), WithSpanningRule(SpanWrapping)},
+				built:   [][]int{{1, 0}, {1, 1}, {1, 2}},
+			},
+			want:         []int{},
+			wantComplete: false,
+		},
+		{
+			name: "wrapping diagonally",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithBoundaries(Periodic, Periodic), WithSpanningRule(SpanAxis(0))},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {2, 2}, {0, 2}},
+			},
+			want:         []int{0, 1},
+			wantComplete: true,
+		},
+		{
+			name: "torus 1st axis",
+			fields: fields{
+				Lengths: []int{3, 3},
+				opts:    []Option{WithBoundaries(Periodic, Periodic)},
+				built:   [][]int{{0, 0}, {1, 0}, {1, 1}},
+			},
+			want:         []int{},
+			wantComplete: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, tt.fields.opts, tt.fields.built...)
+			if got := o.SpanningAxes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Orthotope.SpanningAxes() = %v, want %v", got, tt.want)
+			}
+			got, err := o.BridgeComplete()
+			if err != nil {
+				t.Fatalf("Orthotope.BridgeComplete() error = %v", err)
+			}
+			if got != tt.wantComplete {
+				t.Errorf("Orthotope.BridgeComplete() = %v, want %v", got, tt.wantComplete)
+			}
+			checkComponents(t, o)
+		})
+	}
+}
+
+func TestOrthotope_SpanningAxes_matchesBFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []int
+		opts    []Option
+	}{
+		{name: "2D torus", lengths: []int{7, 6}, opts: []Option{WithBoundaries(Periodic, Periodic), WithSpanningRule(SpanAll)}},
+		{name: "2D thin torus", lengths: []int{2, 9}, opts: []Option{WithBoundaries(Periodic, Periodic), WithSpanningRule(SpanAny)}},
+		{name: "2D cylinder Moore", lengths: []int{8, 6}, opts: []Option{WithBoundaries(Open, Periodic), WithNeighborhood(Moore), WithSpanningRule(SpanWrapping)}},
+		{name: "3D torus", lengths: []int{4, 3, 5}, opts: []Option{WithBoundaries(Periodic, Periodic, Periodic), WithSpanningRule(SpanWrapping)}},
+		{name: "3D mixed stencil", lengths: []int{4, 5, 3}, opts: []Option{WithBoundaries(Open, Periodic, Open), WithNeighborhood(Stencil([]int{0, 2, 1}, []int{1, 0, 0})), WithSpanningRule(SpanAll)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(2))
+			o := newTestOrthotope(t, tt.lengths, tt.opts)
+			for step := 0; step < 10*len(o.cells); step++ {
+				var err error
+				if o.nBuilt == 0 || (o.nBuilt < len(o.cells) && rng.Float64() < 0.55) {
+					_, err = o.BuildRandom()
+				} else {
+					_, err = o.DemolishRandom()
+				}
+				if err != nil {
+					t.Fatalf("step %d: error = %v", step, err)
+				}
+				got, _ := o.BridgeComplete()
+				want, err := o.bridgeCompleteBFS()
+				if err != nil {
+					t.Fatalf("Orthotope.bridgeCompleteBFS() error = %v", err)
+				}
+				if got != want {
+					t.Fatalf("step %d: Orthotope.BridgeComplete() = %v, bridgeCompleteBFS() = %v", step, got, want)
+				}
+				if step%7 == 0 {
+					checkComponents(t, o)
+				}
+			}
+		})
+	}
+}
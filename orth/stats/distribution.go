@@ -0,0 +1,195 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+var ErrInvalidBase = errors.New("bin base must be finite and exceed 1")
+
+// Distribution is the cluster size distribution n_s: the number of clusters
+// of s bridges per location, averaged over samples with a fixed fraction of
+// locations occupied. Clusters spanning any dimension are left out.
+type Distribution struct {
+	// Fraction is the occupied fraction, Size the number of locations and
+	// Samples the number of orthotopes averaged over.
+	Fraction float64
+	Size     int
+	Samples  int
+	// N maps each cluster size s to n_s.
+	N map[int]float64
+	// MeanSize is the mean size of the cluster holding a random bridge, not
+	// counting spanning ones: sum s^2 n_s / sum s n_s.
+	MeanSize float64
+	// Spanning is the fraction of samples with a spanning cluster.
+	Spanning float64
+}
+
+// Bin is a range of cluster sizes from Low to High inclusive, with Center
+// their geometric mean and N the mean of n_s over the range.
+type Bin struct {
+	Low    int
+	High   int
+	Center float64
+	N      float64
+}
+
+// ClusterSizes builds round(p N) random bridges on each of samples orthotopes
+// with side lengths lengths configured by opts and measures the sizes of their
+// clusters.
+func (r *Runner) ClusterSizes(ctx context.Context, lengths []int, p float64, samples int, opts ...orth.Option) (*Distribution, error) {
+
+	if !(p >= 0 && p <= 1) {
+		return nil, fmt.Errorf("p = %v: %w", p, ErrOutOfRange)
+	}
+	if samples <= 0 {
+		return nil, fmt.Errorf("%d samples: %w", samples, ErrNoTrials)
+	}
+
+	size := locations(lengths)
+	built := int(math.Round(p * float64(size)))
+
+	counts := make([]map[int]int, samples)
+	spanning := make([]bool, samples)
+	err := r.each(ctx, samples, func(ctx context.Context, k int, src orth.Source) error {
+		o, err := orth.New(lengths, append(append([]orth.Option{}, opts...), orth.WithSource(src))...)
+		if err != nil {
+			return err
+		}
+		for n := 0; n < built; n++ {
+			if n%checkEvery == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			if _, err := o.BuildRandom(); err != nil {
+				return err
+			}
+		}
+
+		counts[k] = map[int]int{}
+		for _, c := range o.Clusters().Clusters {
+			if len(c.Spans) > 0 {
+				spanning[k] = true
+				continue
+			}
+			counts[k][c.Size]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Distribution{
+		Fraction: p,
+		Size:     size,
+		Samples:  samples,
+		N:        map[int]float64{},
+	}
+	total := make(map[int]int)
+	for k := range counts {
+		for s, c := range counts[k] {
+			total[s] += c
+		}
+		if spanning[k] {
+			d.Spanning++
+		}
+	}
+	d.Spanning /= float64(samples)
+
+	// Sum in order of size, so the result does not depend on map order.
+	sizes := make([]int, 0, len(total))
+	for s := range total {
+		sizes = append(sizes, s)
+	}
+	sort.Ints(sizes)
+
+	var first, second float64
+	for _, s := range sizes {
+		n := float64(total[s]) / float64(size*samples)
+		d.N[s] = n
+		first += float64(s) * n
+		second += float64(s) * float64(s) * n
+	}
+	if first > 0 {
+		d.MeanSize = second / first
+	}
+
+	return d, nil
+}
+
+// LogBins returns N averaged over bins of sizes growing by a factor of base,
+// which must be finite and exceed 1. The first bin starts at size 1 and each
+// later one right after the last; a bin starting at low ends before low*base
+// but holds at least one size, so base 2 gives [1, 2), [2, 4), [4, 8), ...
+// Bins past the largest cluster are left out.
+func (d *Distribution) LogBins(base float64) ([]Bin, error) {
+
+	if !(base > 1) || math.IsInf(base, 1) {
+		return nil, fmt.Errorf("base %v: %w", base, ErrInvalidBase)
+	}
+
+	largest := 0
+	for s := range d.N {
+		if s > largest {
+			largest = s
+		}
+	}
+
+	var bins []Bin
+	for low := 1; low <= largest; {
+		high := low
+		if edge := math.Ceil(float64(low)*base) - 1; edge >= math.MaxInt32 {
+			high = math.MaxInt32
+		} else if int(edge) > low {
+			high = int(edge)
+		}
+
+		// Sizes past the largest cluster add nothing to the sum.
+		var sum float64
+		for s := low; s <= high && s <= largest; s++ {
+			sum += d.N[s]
+		}
+		bins = append(bins, Bin{
+			Low:    low,
+			High:   high,
+			Center: math.Sqrt(float64(low) * float64(high)),
+			N:      sum / float64(high-low+1),
+		})
+		low = high + 1
+	}
+
+	return bins, nil
+}
+
+// WriteTSV writes d binned by powers of 2 as tab separated columns low, high,
+// center and n with a header line, followed by a line starting with # giving
+// MeanSize and Spanning. On a log-log plot of n against center, n_s ∝ s^-τ is
+// a line of slope -τ.
+func (d *Distribution) WriteTSV(w io.Writer) error {
+
+	if _, err := fmt.Fprintf(w, "low\thigh\tcenter\tn\n"); err != nil {
+		return err
+	}
+	bins, err := d.LogBins(2)
+	if err != nil {
+		return err
+	}
+	for _, b := range bins {
+		if _, err := fmt.Fprintf(w, "%d\t%d\t%g\t%g\n", b.Low, b.High, b.Center, b.N); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "# mean size = %g\n# spanning = %g\n", d.MeanSize, d.Spanning); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDistribution_LogBins(t *testing.T) {
+	tests := []struct {
+		name    string
+		N       map[int]float64
+		base    float64
+		want    []Bin
+		wantErr error
+	}{
+		{
+			name: "empty",
+			N:    map[int]float64{},
+			base: 2,
+		},
+		{
+			name: "powers of 2",
+			N:    map[int]float64{1: 0.5, 2: 0.25, 3: 0.25, 5: 0.4},
+			base: 2,
+			want: []Bin{
+				{Low: 1, High: 1, Center: 1, N: 0.5},
+				{Low: 2, High: 3, Center: 2.449489742783178, N: 0.25},
+				{Low: 4, High: 7, Center: 5.291502622129181, N: 0.1},
+			},
+		},
+		{
+			name: "narrow bins skip empty ranges",
+			N:    map[int]float64{1: 1, 2: 1},
+			base: 1.5,
+			want: []Bin{
+				{Low: 1, High: 1, Center: 1, N: 1},
+				{Low: 2, High: 2, Center: 2, N: 1},
+			},
+		},
+		{
+			name: "base barely above 1",
+			N:    map[int]float64{1: 1, 1000: 1},
+			base: math.Nextafter(1, 2),
+			want: func() []Bin {
+				var bins []Bin
+				for s := 1; s <= 1000; s++ {
+					bins = append(bins, Bin{Low: s, High: s, Center: math.Sqrt(float64(s) * float64(s))})
+				}
+				bins[0].N, bins[999].N = 1, 1
+				return bins
+			}(),
+		},
+		{
+			name: "huge base",
+			N:    map[int]float64{1: 1, 2: 1},
+			base: math.MaxFloat64,
+			want: []Bin{
+				{Low: 1, High: math.MaxInt32, Center: math.Sqrt(math.MaxInt32), N: 2 / float64(math.MaxInt32)},
+			},
+		},
+		{
+			name:    "base 1",
+			N:       map[int]float64{1: 1, 2: 1},
+			base:    1,
+			wantErr: ErrInvalidBase,
+		},
+		{
+			name:    "base below 1",
+			N:       map[int]float64{1: 1, 2: 1},
+			base:    0.5,
+			wantErr: ErrInvalidBase,
+		},
+		{
+			name:    "infinite base",
+			N:       map[int]float64{1: 1, 2: 1},
+			base:    math.Inf(1),
+			wantErr: ErrInvalidBase,
+		},
+		{
+			name:    "NaN base",
+			N:       map[int]float64{1: 1, 2: 1},
+			base:    math.NaN(),
+			wantErr: ErrInvalidBase,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Distribution{N: tt.N}
+			got, err := d.LogBins(tt.base)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Distribution.LogBins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Distribution.LogBins() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistribution_WriteTSV(t *testing.T) {
+
+	d := &Distribution{N: map[int]float64{1: 0.5, 2: 0.25, 3: 0.25}, MeanSize: 2, Spanning: 0.5}
+	var buf bytes.Buffer
+	if err := d.WriteTSV(&buf); err != nil {
+		t.Fatalf("Distribution.WriteTSV() error = %v", err)
+	}
+	want := "low\thigh\tcenter\tn\n1\t1\t1\t0.5\n2\t3\t2.449489742783178\t0.25\n# mean size = 2\n# spanning = 0.5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Distribution.WriteTSV() = %q, want %q", got, want)
+	}
+}
+
+func TestRunner_ClusterSizes(t *testing.T) {
+
+	r, err := NewRunner(WithSeed(5))
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Full 1D: one spanning cluster, left out.
+	got, err := r.ClusterSizes(ctx, []int{10}, 1, 3)
+	if err != nil {
+		t.Fatalf("Runner.ClusterSizes() error = %v", err)
+	}
+	if len(got.N) != 0 || got.Spanning != 1 || got.MeanSize != 0 {
+		t.Errorf("Runner.ClusterSizes() full = %+v", got)
+	}
+
+	// Below the threshold every bridge is in some counted cluster, so
+	// sum s n_s is the occupied fraction.
+	got, err = r.ClusterSizes(ctx, []int{40, 40}, 0.3, 20)
+	if err != nil {
+		t.Fatalf("Runner.ClusterSizes() error = %v", err)
+	}
+	if got.Spanning != 0 {
+		t.Fatalf("Runner.ClusterSizes() spanning at p 0.3 = %v", got.Spanning)
+	}
+	var first float64
+	for s, n := range got.N {
+		first += float64(s) * n
+	}
+	if !near(first, 0.3) {
+		t.Errorf("Runner.ClusterSizes() sum s n_s = %v, want 0.3", first)
+	}
+	if got.MeanSize < 1 || got.N[1] <= got.N[2] {
+		t.Errorf("Runner.ClusterSizes() mean size %v, n_1 %v, n_2 %v", got.MeanSize, got.N[1], got.N[2])
+	}
+
+	one, err := NewRunner(WithSeed(5), WithWorkers(1))
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	again, err := one.ClusterSizes(ctx, []int{40, 40}, 0.3, 20)
+	if err != nil {
+		t.Fatalf("Runner.ClusterSizes() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, again) {
+		t.Errorf("Runner.ClusterSizes() differs with 1 worker")
+	}
+
+	if _, err := r.ClusterSizes(ctx, []int{10}, -0.1, 3); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Runner.ClusterSizes() with p -0.1 error = %v, want %v", err, ErrOutOfRange)
+	}
+	if _, err := r.ClusterSizes(ctx, []int{10}, 0.5, 0); !errors.Is(err, ErrNoTrials) {
+		t.Errorf("Runner.ClusterSizes() with 0 samples error = %v, want %v", err, ErrNoTrials)
+	}
+}
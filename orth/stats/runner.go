@@ -0,0 +1,195 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+// TrialFunc runs one trial drawing its random choices from src and returns its
+// sample. It should return ctx's error promptly once ctx is done.
+type TrialFunc func(ctx context.Context, src orth.Source) (float64, error)
+
+// Runner spreads independent trials across a pool of goroutines. Trial k
+// draws from its own Source seeded by TrialSeed(seed, k), so the samples are
+// the same however many workers run them.
+type Runner struct {
+	workers  int
+	seed     int64
+	progress func(done, total int)
+}
+
+// RunnerOption configures a Runner created by NewRunner.
+type RunnerOption func(*Runner)
+
+// WithWorkers sets how many trials run at once. The default is
+// runtime.GOMAXPROCS(0).
+func WithWorkers(n int) RunnerOption {
+	return func(r *Runner) {
+		r.workers = n
+	}
+}
+
+// WithSeed sets the seed every trial's seed is derived from. The default is 1.
+func WithSeed(seed int64) RunnerOption {
+	return func(r *Runner) {
+		r.seed = seed
+	}
+}
+
+// WithProgress sets a callback told after each finished trial how many of the
+// total are done. It is called from one goroutine at a time, with done
+// increasing by one each call.
+func WithProgress(f func(done, total int)) RunnerOption {
+	return func(r *Runner) {
+		r.progress = f
+	}
+}
+
+// NewRunner returns a Runner configured by opts.
+func NewRunner(opts ...RunnerOption) (*Runner, error) {
+
+	r := &Runner{
+		workers: runtime.GOMAXPROCS(0),
+		seed:    1,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.workers < 1 {
+		return nil, fmt.Errorf("%d workers: %w", r.workers, orth.ErrInvalidOption)
+	}
+
+	return r, nil
+}
+
+// TrialSeed returns the seed of trial k of a run seeded by seed, mixing the two
+// with SplitMix64 so that neighboring trials get unrelated streams.
+func TrialSeed(seed int64, k int) int64 {
+
+	z := uint64(seed) + uint64(k+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return int64(z ^ (z >> 31))
+}
+
+// Run runs trials trials of trial and returns their samples, indexed by trial.
+// It stops at the first failing trial and returns its error, or once ctx is
+// done and returns ctx's error.
+func (r *Runner) Run(ctx context.Context, trials int, trial TrialFunc) ([]float64, error) {
+
+	if trials <= 0 {
+		return nil, fmt.Errorf("%d trials: %w", trials, ErrNoTrials)
+	}
+
+	samples := make([]float64, trials)
+	err := r.each(ctx, trials, func(ctx context.Context, k int, src orth.Source) error {
+		s, err := trial(ctx, src)
+		samples[k] = s
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// each calls trial for each of trials trials k, from r's pool of goroutines, as
+// Run does. trial may write to slots indexed by k without locking.
+func (r *Runner) each(ctx context.Context, trials int, trial func(ctx context.Context, k int, src orth.Source) error) error {
+
+	if trials <= 0 {
+		return fmt.Errorf("%d trials: %w", trials, ErrNoTrials)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		k   int
+		err error
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for k := 0; k < trials; k++ {
+			select {
+			case jobs <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for w := 0; w < r.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				src := rand.New(rand.NewSource(TrialSeed(r.seed, k)))
+				err := trial(ctx, k, src)
+				select {
+				case results <- result{k: k, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Collect on this goroutine, so progress is never called concurrently.
+	var done int
+	var firstErr error
+	for res := range results {
+		if firstErr != nil {
+			continue
+		}
+		if res.err != nil {
+			firstErr = fmt.Errorf("trial %d: %w", res.k, res.err)
+			cancel()
+			continue
+		}
+		done++
+		if r.progress != nil {
+			r.progress(done, trials)
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if done < trials {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Threshold is the parallel form of the package level Threshold, with each
+// trial's Source set by r, overriding any in opts.
+func (r *Runner) Threshold(ctx context.Context, lengths []int, trials int, opts ...orth.Option) (*Estimate, error) {
+
+	samples, err := r.Run(ctx, trials, func(ctx context.Context, src orth.Source) (float64, error) {
+		return trial(ctx, lengths, append(append([]orth.Option{}, opts...), orth.WithSource(src))...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := Summarize(samples)
+	return &e, nil
+}
@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+func TestNewRunner(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []RunnerOption
+		wantErr error
+	}{
+		{name: "default"},
+		{name: "one worker", opts: []RunnerOption{WithWorkers(1), WithSeed(5)}},
+		{name: "no workers", opts: []RunnerOption{WithWorkers(0)}, wantErr: orth.ErrInvalidOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRunner(tt.opts...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRunner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrialSeed(t *testing.T) {
+
+	seen := map[int64]bool{}
+	for _, seed := range []int64{0, 1, -1} {
+		for k := 0; k < 100; k++ {
+			s := TrialSeed(seed, k)
+			if seen[s] {
+				t.Fatalf("TrialSeed(%d, %d) = %d repeats", seed, k, s)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+func TestRunner_Threshold_workersAgree(t *testing.T) {
+
+	var want []float64
+	for _, workers := range []int{1, 3, 8} {
+		r, err := NewRunner(WithWorkers(workers), WithSeed(42))
+		if err != nil {
+			t.Fatalf("NewRunner() error = %v", err)
+		}
+		got, err := r.Threshold(context.Background(), []int{12, 12}, 40)
+		if err != nil {
+			t.Fatalf("Runner.Threshold() with %d workers error = %v", workers, err)
+		}
+		if want == nil {
+			want = got.Samples
+			continue
+		}
+		if !reflect.DeepEqual(got.Samples, want) {
+			t.Errorf("Runner.Threshold() with %d workers = %v, want %v", workers, got.Samples, want)
+		}
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+
+	failure := errors.New("failure")
+	tests := []struct {
+		name    string
+		trials  int
+		trial   TrialFunc
+		cancel  int
+		want    []float64
+		wantErr error
+	}{
+		{
+			name:   "samples by trial",
+			trials: 4,
+			trial: func(ctx context.Context, src orth.Source) (float64, error) {
+				return 0.5, nil
+			},
+			want: []float64{0.5, 0.5, 0.5, 0.5},
+		},
+		{
+			name:    "no trials",
+			trials:  0,
+			wantErr: ErrNoTrials,
+		},
+		{
+			name:    "negative trials",
+			trials:  -1,
+			wantErr: ErrNoTrials,
+		},
+		{
+			name:   "failing trial",
+			trials: 10,
+			trial: func(ctx context.Context, src orth.Source) (float64, error) {
+				return 0, failure
+			},
+			wantErr: failure,
+		},
+		{
+			name:   "canceled by progress",
+			trials: 1000,
+			trial: func(ctx context.Context, src orth.Source) (float64, error) {
+				return 0, ctx.Err()
+			},
+			cancel:  3,
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var calls []int
+			r, err := NewRunner(WithWorkers(4), WithProgress(func(done, total int) {
+				if total != tt.trials {
+					t.Errorf("progress total = %d, want %d", total, tt.trials)
+				}
+				calls = append(calls, done)
+				if done == tt.cancel {
+					cancel()
+				}
+			}))
+			if err != nil {
+				t.Fatalf("NewRunner() error = %v", err)
+			}
+
+			got, err := r.Run(ctx, tt.trials, tt.trial)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Runner.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Runner.Run() = %v, want %v", got, tt.want)
+			}
+			for k, done := range calls {
+				if done != k+1 {
+					t.Fatalf("progress calls = %v, want 1, 2, ...", calls)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Threshold_canceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r, err := NewRunner()
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	if _, err := r.Threshold(ctx, []int{50, 50}, 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("Runner.Threshold() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,236 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+var ErrTooFewSizes = errors.New("too few sizes to fit")
+
+// Scaling is a finite-size scaling analysis of the completion threshold of
+// hypercubes of several side lengths L.
+//
+// Below p_c larger hypercubes are less likely to be complete and above it more
+// likely, so the completion probabilities R_L(p) of successive sizes cross
+// near p_c. Crossings locates each crossing.
+//
+// Separately, the mean p(L) and width w(L) of the fraction occupied at
+// completion scale as
+//
+//	w(L) ∝ L^(-1/ν)  and  p(L) - p_c ∝ L^(-1/ν),
+//
+// so ν comes from a straight line fit of log w against log L, and p_c(∞) is
+// where the fit of p(L) against L^(-1/ν) meets L = ∞.
+type Scaling struct {
+	// Sizes lists the side lengths L, and Estimates the threshold at each.
+	Sizes     []int
+	Estimates []Estimate
+	// Crossings lists, for each pair of successive Sizes, the p at which
+	// their R_L(p) cross, or NaN if they do not, and CrossingErrs the
+	// standard error of each.
+	Crossings    []float64
+	CrossingErrs []float64
+	// Nu is the correlation length exponent ν and NuErr its standard error.
+	Nu    float64
+	NuErr float64
+	// Pc is the threshold p_c(∞) extrapolated from the means and PcErr its
+	// standard error, including that carried over from ν.
+	Pc    float64
+	PcErr float64
+}
+
+// Scale runs trials threshold trials on hypercubes of dims dimensions for each
+// side length in sizes, of which it needs at least 3, and fits how they scale.
+// The trials at each size are seeded apart from those at the others, and the
+// completion probabilities compared for Crossings are estimated from the same
+// trials, in the manner of Sweep.
+func (r *Runner) Scale(ctx context.Context, dims int, sizes []int, trials int, opts ...orth.Option) (*Scaling, error) {
+
+	if len(sizes) < 3 {
+		return nil, fmt.Errorf("%d sizes, want 3: %w", len(sizes), ErrTooFewSizes)
+	}
+	if trials < 2 {
+		return nil, fmt.Errorf("%d trials, want 2 for a width: %w", trials, ErrNoTrials)
+	}
+
+	s := &Scaling{Sizes: append([]int{}, sizes...)}
+	var curves []*completion
+	for k, l := range sizes {
+		lengths := make([]int, dims)
+		for d := range lengths {
+			lengths[d] = l
+		}
+
+		sub := *r
+		sub.seed = TrialSeed(r.seed, -1-k)
+		e, err := sub.Threshold(ctx, lengths, trials, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("size %d: %w", l, err)
+		}
+		s.Estimates = append(s.Estimates, *e)
+
+		size := locations(lengths)
+		steps := make([]int, len(e.Samples))
+		for t, f := range e.Samples {
+			steps[t] = int(math.Round(f * float64(size)))
+		}
+		curves = append(curves, newCompletion(size, steps))
+	}
+
+	for k := 1; k < len(curves); k++ {
+		p, err := crossing(curves[k-1], curves[k])
+		s.Crossings = append(s.Crossings, p)
+		s.CrossingErrs = append(s.CrossingErrs, err)
+	}
+
+	if err := s.fit(trials); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// crossingScan is the number of intervals crossing checks for a crossing before
+// narrowing it down.
+const crossingScan = 200
+
+// crossing returns the first p strictly between 0 and 1 at which the curves a
+// and b cross, or NaN if they do not, and its standard error from that of
+// each curve there.
+func crossing(a, b *completion) (float64, float64) {
+
+	diff := func(p float64) float64 { return a.at(p) - b.at(p) }
+
+	// Scan for a change of sign, skipping where both curves are flat at 0 or 1.
+	lo, hi := math.NaN(), math.NaN()
+	var last float64
+	for k := 1; k < crossingScan; k++ {
+		p := float64(k) / crossingScan
+		d := diff(p)
+		if d == 0 {
+			continue
+		}
+		if last != 0 && (d > 0) != (last > 0) {
+			hi = p
+			break
+		}
+		lo, last = p, d
+	}
+	if math.IsNaN(hi) {
+		return math.NaN(), math.NaN()
+	}
+
+	// Bisect down to well below the statistical error.
+	for k := 0; k < 40; k++ {
+		mid := (lo + hi) / 2
+		if d := diff(mid); d != 0 && (d > 0) == (last > 0) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	p := (lo + hi) / 2
+
+	// The error in the difference over its slope is the error in where it is 0.
+	const h = 1e-3
+	slope := (diff(p+h) - diff(p-h)) / (2 * h)
+	ra, rb := a.at(p), b.at(p)
+	variance := ra*(1-ra)/float64(a.trials) + rb*(1-rb)/float64(b.trials)
+
+	return p, math.Sqrt(variance) / math.Abs(slope)
+}
+
+// fit fits Nu and Pc to the Estimates of trials samples each.
+func (s *Scaling) fit(trials int) error {
+
+	n := len(s.Sizes)
+	logL := make([]float64, n)
+	logW := make([]float64, n)
+	wW := make([]float64, n)
+	for k, e := range s.Estimates {
+		width := e.StdErr * math.Sqrt(float64(trials))
+		if !(width > 0) {
+			return fmt.Errorf("size %d has no spread to scale: %w", s.Sizes[k], ErrTooFewSizes)
+		}
+		logL[k] = math.Log(float64(s.Sizes[k]))
+		logW[k] = math.Log(width)
+		// The sample standard deviation has relative error 1/sqrt(2(n-1)).
+		wW[k] = 2 * float64(trials-1)
+	}
+
+	_, slope, _, slopeErr := fitLine(logL, logW, wW)
+	s.Nu = -1 / slope
+	s.NuErr = slopeErr / (slope * slope)
+
+	pc := func(nu float64) (float64, float64) {
+		x := make([]float64, n)
+		y := make([]float64, n)
+		w := make([]float64, n)
+		for k, e := range s.Estimates {
+			x[k] = math.Pow(float64(s.Sizes[k]), -1/nu)
+			y[k] = e.Mean
+			w[k] = 1 / (e.StdErr * e.StdErr)
+		}
+		a, _, aErr, _ := fitLine(x, y, w)
+		return a, aErr
+	}
+
+	// Carry the uncertainty of ν over by refitting one standard error away.
+	var fitErr float64
+	s.Pc, fitErr = pc(s.Nu)
+	lo, _ := pc(s.Nu - s.NuErr)
+	hi, _ := pc(s.Nu + s.NuErr)
+	nuErr := math.Max(math.Abs(lo-s.Pc), math.Abs(hi-s.Pc))
+	s.PcErr = math.Sqrt(fitErr*fitErr + nuErr*nuErr)
+
+	return nil
+}
+
+// fitLine fits y = a + b x by least squares with weights w, the inverse
+// variances of y, and returns a, b and their standard errors.
+func fitLine(x, y, w []float64) (float64, float64, float64, float64) {
+
+	var sw, sx, sy, sxx, sxy float64
+	for k := range x {
+		sw += w[k]
+		sx += w[k] * x[k]
+		sy += w[k] * y[k]
+		sxx += w[k] * x[k] * x[k]
+		sxy += w[k] * x[k] * y[k]
+	}
+	det := sw*sxx - sx*sx
+	a := (sxx*sy - sx*sxy) / det
+	b := (sw*sxy - sx*sy) / det
+
+	return a, b, math.Sqrt(sxx / det), math.Sqrt(sw / det)
+}
+
+// WriteTSV writes s as tab separated columns L, mean, stderr and width with a
+// header line, followed by the crossings and the fit in lines starting with #.
+func (s *Scaling) WriteTSV(w io.Writer) error {
+
+	if _, err := fmt.Fprintf(w, "L\tmean\tstderr\twidth\n"); err != nil {
+		return err
+	}
+	for k, e := range s.Estimates {
+		width := e.StdErr * math.Sqrt(float64(len(e.Samples)))
+		if _, err := fmt.Fprintf(w, "%d\t%g\t%g\t%g\n", s.Sizes[k], e.Mean, e.StdErr, width); err != nil {
+			return err
+		}
+	}
+	for k, p := range s.Crossings {
+		if _, err := fmt.Fprintf(w, "# crossing L = %d, %d: p = %g ± %g\n", s.Sizes[k], s.Sizes[k+1], p, s.CrossingErrs[k]); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "# nu = %g ± %g\n# p_c = %g ± %g\n", s.Nu, s.NuErr, s.Pc, s.PcErr); err != nil {
+		return err
+	}
+
+	return nil
+}
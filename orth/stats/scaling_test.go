@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func Test_fitLine(t *testing.T) {
+
+	x := []float64{0, 1, 2, 3}
+	y := []float64{2, 5, 8, 11}
+	w := []float64{1, 1, 1, 1}
+	a, b, aErr, bErr := fitLine(x, y, w)
+	if !near(a, 2) || !near(b, 3) {
+		t.Errorf("fitLine() = %v + %v x, want 2 + 3 x", a, b)
+	}
+	// With unit variances, var(b) = 1/sum((x-mean)^2) = 1/5 and
+	// var(a) = sum(x^2)/(n sum((x-mean)^2)) = 14/20.
+	if !near(aErr, math.Sqrt(0.7)) || !near(bErr, math.Sqrt(0.2)) {
+		t.Errorf("fitLine() errors = %v, %v, want %v, %v", aErr, bErr, math.Sqrt(0.7), math.Sqrt(0.2))
+	}
+}
+
+func TestScaling_fit(t *testing.T) {
+
+	// Exact scaling with ν = 4/3 and p_c = 0.6.
+	const trials = 100
+	s := &Scaling{Sizes: []int{10, 20, 40, 80}}
+	for _, l := range s.Sizes {
+		x := math.Pow(float64(l), -0.75)
+		s.Estimates = append(s.Estimates, Estimate{Mean: 0.6 + 0.5*x, StdErr: x / math.Sqrt(trials)})
+	}
+
+	if err := s.fit(trials); err != nil {
+		t.Fatalf("Scaling.fit() error = %v", err)
+	}
+	if !near(s.Nu, 4.0/3) || !near(s.Pc, 0.6) {
+		t.Errorf("Scaling.fit() ν = %v, p_c = %v, want 4/3, 0.6", s.Nu, s.Pc)
+	}
+	if !(s.NuErr > 0) || !(s.PcErr > 0) {
+		t.Errorf("Scaling.fit() errors ν ± %v, p_c ± %v, want positive", s.NuErr, s.PcErr)
+	}
+}
+
+func Test_crossing(t *testing.T) {
+
+	// steps returns trials completing evenly from from to to bridges.
+	steps := func(from, to, trials int) []int {
+		s := make([]int, trials)
+		for k := range s {
+			s[k] = from + k*(to-from)/(trials-1)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		a, b    *completion
+		want    float64
+		wantNaN bool
+	}{
+		{
+			name: "narrower about the same middle",
+			a:    newCompletion(1000, steps(400, 800, 101)),
+			b:    newCompletion(1000, steps(550, 650, 101)),
+			want: 0.6,
+		},
+		{
+			name:    "same curve",
+			a:       newCompletion(1000, steps(400, 800, 101)),
+			b:       newCompletion(1000, steps(400, 800, 101)),
+			wantNaN: true,
+		},
+		{
+			name:    "always ahead",
+			a:       newCompletion(1000, steps(300, 400, 101)),
+			b:       newCompletion(1000, steps(600, 700, 101)),
+			wantNaN: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := crossing(tt.a, tt.b)
+			if tt.wantNaN {
+				if !math.IsNaN(got) || !math.IsNaN(gotErr) {
+					t.Errorf("crossing() = %v ± %v, want NaN", got, gotErr)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 0.005 || !(gotErr > 0) {
+				t.Errorf("crossing() = %v ± %v, want %v", got, gotErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunner_Scale(t *testing.T) {
+
+	r, err := NewRunner(WithSeed(9))
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	got, err := r.Scale(context.Background(), 2, []int{8, 16, 32}, 300)
+	if err != nil {
+		t.Fatalf("Runner.Scale() error = %v", err)
+	}
+	if len(got.Estimates) != 3 {
+		t.Fatalf("Runner.Scale() has %d estimates, want 3", len(got.Estimates))
+	}
+	// Site percolation on the square lattice: p_c = 0.5927, ν = 4/3.
+	if math.Abs(got.Pc-0.5927) > 0.05 || got.Nu < 0.5 || got.Nu > 3 {
+		t.Errorf("Runner.Scale() p_c = %v ± %v, ν = %v ± %v", got.Pc, got.PcErr, got.Nu, got.NuErr)
+	}
+
+	if len(got.Crossings) != 2 || len(got.CrossingErrs) != 2 {
+		t.Fatalf("Runner.Scale() crossings = %v ± %v, want 2", got.Crossings, got.CrossingErrs)
+	}
+	for k, p := range got.Crossings {
+		if math.Abs(p-0.5927) > 0.05 || !(got.CrossingErrs[k] > 0) {
+			t.Errorf("Runner.Scale() crossing %d = %v ± %v, want near 0.5927", k, p, got.CrossingErrs[k])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteTSV(&buf); err != nil {
+		t.Fatalf("Scaling.WriteTSV() error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 8 || lines[0] != "L\tmean\tstderr\twidth" {
+		t.Errorf("Scaling.WriteTSV() = %q", buf.String())
+	}
+
+	if _, err := r.Scale(context.Background(), 2, []int{8, 16}, 300); !errors.Is(err, ErrTooFewSizes) {
+		t.Errorf("Runner.Scale() with 2 sizes error = %v, want %v", err, ErrTooFewSizes)
+	}
+	if _, err := r.Scale(context.Background(), 2, []int{8, 16, 32}, 1); !errors.Is(err, ErrNoTrials) {
+		t.Errorf("Runner.Scale() with 1 trial error = %v, want %v", err, ErrNoTrials)
+	}
+}
@@ -0,0 +1,172 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+var ErrOutOfRange = errors.New("probability out of range")
+
+// Curve is the probability R(p) that an orthotope is complete when each
+// location holds a bridge independently with probability p.
+type Curve struct {
+	// Size is the number of locations and Trials the number of sweeps.
+	Size   int
+	Trials int
+	// P lists the occupation probabilities and R the completion probability
+	// at each.
+	P []float64
+	R []float64
+}
+
+// WriteTSV writes c as tab separated columns p and R with a header line.
+func (c *Curve) WriteTSV(w io.Writer) error {
+
+	if _, err := fmt.Fprintf(w, "p\tR\n"); err != nil {
+		return err
+	}
+	for k, p := range c.P {
+		if _, err := fmt.Fprintf(w, "%g\t%g\n", p, c.R[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Grid returns n+1 evenly spaced probabilities from 0 to 1, or just 0 if n < 1.
+func Grid(n int) []float64 {
+
+	if n < 1 {
+		return []float64{0}
+	}
+
+	ps := make([]float64, n+1)
+	for k := range ps {
+		ps[k] = float64(k) / float64(n)
+	}
+
+	return ps
+}
+
+// Sweep estimates R(p) at every p in ps in the manner of Newman and Ziff. Each
+// trial builds bridges in a random order until BridgeComplete, recording the
+// number n built then. Since adding bridges never undoes completion, the
+// fraction of trials complete with n bridges estimates the probability R_n of
+// completion with exactly n of the N locations occupied, and
+//
+//	R(p) = sum over n of C(N, n) p^n (1-p)^(N-n) R_n.
+func (r *Runner) Sweep(ctx context.Context, lengths []int, trials int, ps []float64, opts ...orth.Option) (*Curve, error) {
+
+	for _, p := range ps {
+		if !(p >= 0 && p <= 1) {
+			return nil, fmt.Errorf("p = %v: %w", p, ErrOutOfRange)
+		}
+	}
+
+	steps, err := r.Run(ctx, trials, func(ctx context.Context, src orth.Source) (float64, error) {
+		step, _, err := completionStep(ctx, lengths, append(append([]orth.Option{}, opts...), orth.WithSource(src))...)
+		return float64(step), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	size := locations(lengths)
+	counts := make([]int, len(steps))
+	for k, s := range steps {
+		counts[k] = int(s)
+	}
+	rp := newCompletion(size, counts)
+
+	c := &Curve{
+		Size:   size,
+		Trials: trials,
+		P:      append([]float64{}, ps...),
+		R:      make([]float64, len(ps)),
+	}
+	for k, p := range ps {
+		c.R[k] = rp.at(p)
+	}
+
+	return c, nil
+}
+
+// completion is the completion probability R(p) of an orthotope of size
+// locations estimated from the numbers of bridges at which trials completed.
+type completion struct {
+	size   int
+	trials int
+	// completed[n] is the number of trials complete with n bridges.
+	completed []float64
+}
+
+func newCompletion(size int, steps []int) *completion {
+
+	completed := make([]float64, size+1)
+	for _, s := range steps {
+		completed[s]++
+	}
+	for n := 1; n <= size; n++ {
+		completed[n] += completed[n-1]
+	}
+
+	return &completion{size: size, trials: len(steps), completed: completed}
+}
+
+// at returns R(p), leaving out numbers of bridges too unlikely to matter.
+func (c *completion) at(p float64) float64 {
+
+	sd := math.Sqrt(float64(c.size) * p * (1 - p))
+	mean := float64(c.size) * p
+	lo := int(math.Max(0, math.Floor(mean-12*sd-1)))
+	hi := int(math.Min(float64(c.size), math.Ceil(mean+12*sd+1)))
+
+	var sum float64
+	for k, w := range binomialRange(c.size, p, lo, hi) {
+		sum += w * c.completed[lo+k]
+	}
+
+	return sum / float64(c.trials)
+}
+
+// binomial returns the probabilities of 0 to n successes in n trials each
+// succeeding with probability p, computed in log space so large n does not
+// underflow.
+func binomial(n int, p float64) []float64 {
+	return binomialRange(n, p, 0, n)
+}
+
+// binomialRange returns the probabilities of lo to hi successes in n trials
+// each succeeding with probability p.
+func binomialRange(n int, p float64, lo, hi int) []float64 {
+
+	w := make([]float64, hi-lo+1)
+	switch p {
+	case 0:
+		if lo == 0 {
+			w[0] = 1
+		}
+		return w
+	case 1:
+		if hi == n {
+			w[n-lo] = 1
+		}
+		return w
+	}
+
+	lnN, _ := math.Lgamma(float64(n + 1))
+	lp, lq := math.Log(p), math.Log1p(-p)
+	for k := lo; k <= hi; k++ {
+		lk, _ := math.Lgamma(float64(k + 1))
+		lnk, _ := math.Lgamma(float64(n - k + 1))
+		w[k-lo] = math.Exp(lnN - lk - lnk + float64(k)*lp + float64(n-k)*lq)
+	}
+
+	return w
+}
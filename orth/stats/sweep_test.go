@@ -0,0 +1,117 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want []float64
+	}{
+		{name: "zero", n: 0, want: []float64{0}},
+		{name: "one", n: 1, want: []float64{0, 1}},
+		{name: "four", n: 4, want: []float64{0, 0.25, 0.5, 0.75, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Grid(tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Grid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_binomial(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		p    float64
+		want []float64
+	}{
+		{name: "p 0", n: 3, p: 0, want: []float64{1, 0, 0, 0}},
+		{name: "p 1", n: 3, p: 1, want: []float64{0, 0, 0, 1}},
+		{name: "p half", n: 3, p: 0.5, want: []float64{0.125, 0.375, 0.375, 0.125}},
+		{name: "no trials", n: 0, p: 0.3, want: []float64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := binomial(tt.n, tt.p)
+			if len(got) != len(tt.want) {
+				t.Fatalf("binomial() = %v, want %v", got, tt.want)
+			}
+			for k := range got {
+				if !near(got[k], tt.want[k]) {
+					t.Fatalf("binomial() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Large n must neither underflow nor overflow.
+	var sum float64
+	for _, w := range binomial(1000000, 0.59) {
+		sum += w
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("binomial(1000000, 0.59) sums to %v, want 1", sum)
+	}
+}
+
+func TestRunner_Sweep(t *testing.T) {
+
+	r, err := NewRunner(WithSeed(3))
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	// A 1D orthotope completes only once full, so R(p) = p^4.
+	ps := Grid(10)
+	got, err := r.Sweep(context.Background(), []int{4}, 20, ps)
+	if err != nil {
+		t.Fatalf("Runner.Sweep() error = %v", err)
+	}
+	if got.Size != 4 || got.Trials != 20 || !reflect.DeepEqual(got.P, ps) {
+		t.Fatalf("Runner.Sweep() = %+v", got)
+	}
+	for k, p := range ps {
+		if !near(got.R[k], math.Pow(p, 4)) {
+			t.Errorf("Runner.Sweep() R(%v) = %v, want %v", p, got.R[k], math.Pow(p, 4))
+		}
+	}
+
+	// In 2D, R rises from 0 to 1 through about 1/2 near the threshold.
+	got, err = r.Sweep(context.Background(), []int{16, 16}, 200, []float64{0, 0.3, 0.59, 0.9, 1})
+	if err != nil {
+		t.Fatalf("Runner.Sweep() error = %v", err)
+	}
+	if got.R[0] != 0 || got.R[1] > 0.01 || got.R[2] < 0.2 || got.R[2] > 0.8 || got.R[3] < 0.99 || !near(got.R[4], 1) {
+		t.Errorf("Runner.Sweep() R = %v", got.R)
+	}
+
+	if _, err := r.Sweep(context.Background(), []int{4}, 20, []float64{0.5, 1.5}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Runner.Sweep() with p 1.5 error = %v, want %v", err, ErrOutOfRange)
+	}
+	if _, err := r.Sweep(context.Background(), []int{4}, 20, []float64{math.NaN()}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Runner.Sweep() with p NaN error = %v, want %v", err, ErrOutOfRange)
+	}
+}
+
+func TestCurve_WriteTSV(t *testing.T) {
+
+	c := &Curve{Size: 4, Trials: 1, P: []float64{0, 0.5, 1}, R: []float64{0, 0.0625, 1}}
+	var buf bytes.Buffer
+	if err := c.WriteTSV(&buf); err != nil {
+		t.Fatalf("Curve.WriteTSV() error = %v", err)
+	}
+	want := "p\tR\n0\t0\n0.5\t0.0625\n1\t1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Curve.WriteTSV() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+// Package stats runs Monte Carlo experiments on orthotopes to estimate their
+// percolation threshold.
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+var (
+	ErrIncomplete = errors.New("bridge never completed")
+	ErrNoTrials   = errors.New("no trials")
+)
+
+// checkEvery is how many bridges a trial builds between checks for
+// cancellation.
+const checkEvery = 1 << 10
+
+// z95 is the standard normal quantile bounding a two-sided 95% interval.
+const z95 = 1.959963984540054
+
+// Estimate summarizes the fractions of occupied locations at completion over
+// independent trials.
+type Estimate struct {
+	// Mean is the sample mean of Samples.
+	Mean float64
+	// StdErr is the standard error of Mean, or NaN with fewer than 2 samples.
+	StdErr float64
+	// CI95 is the normal approximation 95% confidence interval for the mean,
+	// Mean ± 1.96 StdErr.
+	CI95 [2]float64
+	// Samples holds the fraction of each trial, in the order run.
+	Samples []float64
+}
+
+// Threshold runs trials independent trials on orthotopes with side lengths
+// lengths configured by opts and summarizes the fraction of locations occupied
+// when each completed. Trials draw from the Source in opts one after another,
+// so a seeded Source gives reproducible samples.
+func Threshold(lengths []int, trials int, opts ...orth.Option) (*Estimate, error) {
+
+	if trials <= 0 {
+		return nil, fmt.Errorf("%d trials: %w", trials, ErrNoTrials)
+	}
+
+	samples := make([]float64, trials)
+	for k := range samples {
+		f, err := Trial(lengths, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("trial %d: %w", k, err)
+		}
+		samples[k] = f
+	}
+
+	e := Summarize(samples)
+	return &e, nil
+}
+
+// Trial builds random bridges on a new orthotope with side lengths lengths
+// configured by opts until BridgeComplete, and returns the fraction of
+// locations occupied then. It returns an error wrapping ErrIncomplete if the
+// orthotope fills up without completing.
+func Trial(lengths []int, opts ...orth.Option) (float64, error) {
+	return trial(context.Background(), lengths, opts...)
+}
+
+// trial is Trial, stopping early with ctx's error once ctx is done.
+func trial(ctx context.Context, lengths []int, opts ...orth.Option) (float64, error) {
+
+	step, size, err := completionStep(ctx, lengths, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(step) / float64(size), nil
+}
+
+// completionStep builds random bridges on a new orthotope with side lengths
+// lengths configured by opts until BridgeComplete, and returns how many were
+// built then along with the number of locations.
+func completionStep(ctx context.Context, lengths []int, opts ...orth.Option) (int, int, error) {
+
+	o, err := orth.New(lengths, opts...)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	size := locations(lengths)
+	for built := 0; ; built++ {
+		complete, err := o.BridgeComplete()
+		if err != nil {
+			return 0, 0, err
+		}
+		if complete {
+			return built, size, nil
+		}
+		if built == size {
+			return 0, 0, fmt.Errorf("lengths %v full: %w", lengths, ErrIncomplete)
+		}
+		if built%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, 0, err
+			}
+		}
+		if _, err := o.BuildRandom(); err != nil {
+			return 0, 0, err
+		}
+	}
+}
+
+// Summarize returns the Estimate of samples, which it keeps.
+func Summarize(samples []float64) Estimate {
+
+	e := Estimate{Samples: samples, StdErr: math.NaN()}
+	n := float64(len(samples))
+	if len(samples) == 0 {
+		e.Mean = math.NaN()
+		e.CI95 = [2]float64{math.NaN(), math.NaN()}
+		return e
+	}
+
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	e.Mean = sum / n
+
+	if len(samples) > 1 {
+		var ss float64
+		for _, s := range samples {
+			ss += (s - e.Mean) * (s - e.Mean)
+		}
+		e.StdErr = math.Sqrt(ss/(n-1)) / math.Sqrt(n)
+	}
+	e.CI95 = [2]float64{e.Mean - z95*e.StdErr, e.Mean + z95*e.StdErr}
+
+	return e
+}
+
+// locations returns the number of locations of an orthotope with side lengths
+// lengths.
+func locations(lengths []int) int {
+
+	if len(lengths) == 0 {
+		return 0
+	}
+
+	size := 1
+	for _, l := range lengths {
+		size *= l
+	}
+
+	return size
+}
@@ -0,0 +1,167 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/alowayed/coding-problems/orth"
+)
+
+// near returns whether a and b agree to within 1e-9, or are both NaN.
+func near(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSummarize(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name    string
+		samples []float64
+		want    Estimate
+	}{
+		{
+			name:    "none",
+			samples: []float64{},
+			want:    Estimate{Mean: nan, StdErr: nan, CI95: [2]float64{nan, nan}},
+		},
+		{
+			name:    "one",
+			samples: []float64{0.5},
+			want:    Estimate{Mean: 0.5, StdErr: nan, CI95: [2]float64{nan, nan}},
+		},
+		{
+			name:    "constant",
+			samples: []float64{0.25, 0.25, 0.25},
+			want:    Estimate{Mean: 0.25, StdErr: 0, CI95: [2]float64{0.25, 0.25}},
+		},
+		{
+			name:    "spread",
+			samples: []float64{1, 2, 2, 3, 1.5, 2.5, 2, 2},
+			want:    Estimate{Mean: 2, StdErr: math.Sqrt(2.5/7) / math.Sqrt(8), CI95: [2]float64{2 - z95*math.Sqrt(2.5/7)/math.Sqrt(8), 2 + z95*math.Sqrt(2.5/7)/math.Sqrt(8)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.samples)
+			if !near(got.Mean, tt.want.Mean) || !near(got.StdErr, tt.want.StdErr) ||
+				!near(got.CI95[0], tt.want.CI95[0]) || !near(got.CI95[1], tt.want.CI95[1]) {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got.Samples, tt.samples) {
+				t.Errorf("Summarize().Samples = %v, want %v", got.Samples, tt.samples)
+			}
+		})
+	}
+}
+
+func TestTrial(t *testing.T) {
+	type args struct {
+		lengths []int
+		opts    []orth.Option
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    float64
+		wantErr error
+	}{
+		{
+			name: "1D needs every location",
+			args: args{lengths: []int{7}},
+			want: 1,
+		},
+		{
+			name: "thin 2D needs one location",
+			args: args{lengths: []int{1, 4}},
+			want: 0.25,
+		},
+		{
+			name:    "never complete",
+			args:    args{lengths: []int{}},
+			wantErr: ErrIncomplete,
+		},
+		{
+			name:    "invalid option",
+			args:    args{lengths: []int{3, 3}, opts: []orth.Option{orth.WithSource(nil)}},
+			wantErr: orth.ErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Trial(tt.args.lengths, tt.args.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Trial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Trial() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreshold(t *testing.T) {
+
+	src := func() orth.Option { return orth.WithSource(rand.New(rand.NewSource(11))) }
+
+	got, err := Threshold([]int{20, 20}, 50, src())
+	if err != nil {
+		t.Fatalf("Threshold() error = %v", err)
+	}
+	if len(got.Samples) != 50 {
+		t.Fatalf("Threshold() has %d samples, want 50", len(got.Samples))
+	}
+	for _, s := range got.Samples {
+		if s <= 0 || s > 1 {
+			t.Fatalf("Threshold() sample %v outside (0, 1]", s)
+		}
+	}
+	// Site percolation on the square lattice completes near p = 0.593.
+	if got.Mean < 0.5 || got.Mean > 0.7 {
+		t.Errorf("Threshold().Mean = %v, want near 0.593", got.Mean)
+	}
+	if !(got.CI95[0] < got.Mean && got.Mean < got.CI95[1]) {
+		t.Errorf("Threshold().CI95 = %v does not surround mean %v", got.CI95, got.Mean)
+	}
+
+	again, err := Threshold([]int{20, 20}, 50, src())
+	if err != nil {
+		t.Fatalf("Threshold() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Samples, again.Samples) {
+		t.Errorf("Threshold() with the same seed gave different samples")
+	}
+
+	if _, err := Threshold([]int{20, 20}, 0); !errors.Is(err, ErrNoTrials) {
+		t.Errorf("Threshold() with 0 trials error = %v, want %v", err, ErrNoTrials)
+	}
+}
+
+func TestThreshold_directed(t *testing.T) {
+
+	src := func() orth.Option { return orth.WithSource(rand.New(rand.NewSource(12))) }
+	lengths := []int{100, 20}
+
+	// Diagonal steps only: two square lattices, each rotated 45 degrees.
+	diagonals := orth.WithNeighborhood(orth.Stencil([]int{1, 1}, []int{1, -1}))
+
+	undirected, err := Threshold(lengths, 60, src(), diagonals)
+	if err != nil {
+		t.Fatalf("Threshold() error = %v", err)
+	}
+	directed, err := Threshold(lengths, 60, src(), diagonals, orth.WithDirection(orth.Forward))
+	if err != nil {
+		t.Fatalf("Threshold() directed error = %v", err)
+	}
+
+	// Directed site percolation on the square lattice completes near
+	// p = 0.7055, well above the undirected 0.5927.
+	if directed.Mean < 0.62 || directed.Mean > 0.8 || directed.Mean <= undirected.Mean {
+		t.Errorf("Threshold() directed mean = %v, undirected %v", directed.Mean, undirected.Mean)
+	}
+}
@@ -0,0 +1,88 @@
+package orth
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// svgCell is the side of a cell and svgMargin the room for axis labels, in SVG
+// user units.
+const (
+	svgCell   = 10
+	svgMargin = 40
+)
+
+// WriteSVG writes the cross-section chosen by opts, the first one if several
+// are, as an SVG image. Each cluster gets its own color, clusters completing
+// the bridge are drawn darker and outlined, and the SpanningPath, if any, is
+// marked with dots. As in the README, the 1st plane dimension runs left to
+// right and the 2nd bottom to top from location 0 in the bottom left corner,
+// with ticks labeling locations below and to the left.
+func (o *Orthotope) WriteSVG(w io.Writer, opts ...SliceOption) error {
+
+	v, err := o.newView(opts)
+	if err != nil {
+		return err
+	}
+
+	width := v.width()*svgCell + 2*svgMargin
+	height := v.height()*svgCell + 2*svgMargin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, "<title>%s</title>\n", v.label())
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#f2f2f2" stroke="#999"/>`+"\n",
+		svgMargin, svgMargin, v.width()*svgCell, v.height()*svgCell)
+
+	var path strings.Builder
+	for cy := 0; cy < v.height(); cy++ {
+		for cx := 0; cx < v.width(); cx++ {
+			label, spanning, onPath := v.cell(cx, cy)
+			if label == 0 {
+				continue
+			}
+			px, py := svgMargin+cx*svgCell, svgMargin+cy*svgCell
+			stroke := ""
+			if spanning {
+				stroke = ` stroke="#000" stroke-width="0.6"`
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"%s/>`+"\n",
+				px, py, svgCell, svgCell, hex(clusterColor(label, spanning)), stroke)
+			if onPath {
+				fmt.Fprintf(&path, `<circle cx="%d" cy="%d" r="%d"/>`+"\n", px+svgCell/2, py+svgCell/2, svgCell/4)
+			}
+		}
+	}
+	if path.Len() > 0 {
+		fmt.Fprintf(&b, "<g fill=\"#fff\" stroke=\"#000\" stroke-width=\"0.5\">\n%s</g>\n", path.String())
+	}
+
+	// Ticks along the bottom for the 1st plane dimension and up the left for
+	// the 2nd, at cell centers.
+	bottom := svgMargin + v.height()*svgCell
+	b.WriteString(`<g font-family="sans-serif" font-size="8" fill="#333">` + "\n")
+	for cx := 0; cx < v.width(); cx += tickStep(v.width()) {
+		px := svgMargin + cx*svgCell + svgCell/2
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333"/>`+"\n", px, bottom, px, bottom+4)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%d</text>`+"\n", px, bottom+13, cx)
+	}
+	for y := 0; y < v.height(); y += tickStep(v.height()) {
+		py := bottom - y*svgCell - svgCell/2
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333"/>`+"\n", svgMargin-4, py, svgMargin, py)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" dominant-baseline="middle">%d</text>`+"\n", svgMargin-6, py, y)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">dimension %d</text>`+"\n", svgMargin+v.width()*svgCell/2, bottom+svgMargin*3/4, v.x)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" transform="rotate(-90 %d %d)">dimension %d</text>`+"\n",
+		svgMargin/4, svgMargin+v.height()*svgCell/2, svgMargin/4, svgMargin+v.height()*svgCell/2, v.y)
+	b.WriteString("</g>\n</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// hex returns c as an SVG color such as #1a2b3c.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
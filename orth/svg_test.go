@@ -0,0 +1,134 @@
+package orth
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// svgElements counts the elements of each name in the SVG document s, failing
+// t if it is not well-formed.
+func svgElements(t *testing.T, s string) map[string]int {
+	t.Helper()
+
+	counts := map[string]int{}
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if err != nil {
+			t.Fatalf("SVG not well-formed: %v\n%s", err, s)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			counts[se.Name.Local]++
+		}
+	}
+}
+
+func TestOrthotope_WriteSVG(t *testing.T) {
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		opts        []SliceOption
+		wantRects   int
+		wantCircles int
+		wantTitle   string
+		wantErr     error
+	}{
+		{
+			name:      "empty",
+			fields:    fields{Lengths: []int{4, 3}},
+			wantRects: 1,
+			wantTitle: "[*, *]",
+		},
+		{
+			name: "spanning path",
+			fields: fields{
+				Lengths: []int{3, 3},
+				built:   [][]int{{0, 1}, {1, 1}, {2, 1}, {0, 0}, {2, 2}},
+			},
+			wantRects:   1 + 5,
+			wantCircles: 3,
+			wantTitle:   "[*, *]",
+		},
+		{
+			name: "slice",
+			fields: fields{
+				Lengths: []int{3, 2, 2},
+				built:   [][]int{{0, 0, 0}, {1, 1, 1}, {2, 0, 1}},
+			},
+			opts:      []SliceOption{WithSlice(0, 0, 1)},
+			wantRects: 1 + 2,
+			wantTitle: "[*, *, 1]",
+		},
+		{
+			name:    "1D",
+			fields:  fields{Lengths: []int{3}},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "slice out of bounds",
+			fields:  fields{Lengths: []int{3, 2, 2}},
+			opts:    []SliceOption{WithSlice(0, 0, 2)},
+			wantErr: ErrOutOfBounds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			var buf bytes.Buffer
+			err := o.WriteSVG(&buf, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Orthotope.WriteSVG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := svgElements(t, buf.String())
+			if got["rect"] != tt.wantRects || got["circle"] != tt.wantCircles {
+				t.Errorf("Orthotope.WriteSVG() has %d rects, %d circles, want %d, %d", got["rect"], got["circle"], tt.wantRects, tt.wantCircles)
+			}
+			if !strings.Contains(buf.String(), "<title>"+tt.wantTitle+"</title>") {
+				t.Errorf("Orthotope.WriteSVG() title not %q", tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestOrthotope_WriteSVG_orientation(t *testing.T) {
+
+	// With 2 rows, row 0 is the lower one, from y = 50 to 60.
+	o := newTestOrthotope(t, []int{3, 2}, nil, []int{0, 0}, []int{2, 1})
+	var buf bytes.Buffer
+	if err := o.WriteSVG(&buf); err != nil {
+		t.Fatalf("Orthotope.WriteSVG() error = %v", err)
+	}
+	got := buf.String()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "origin bottom left", want: `<rect x="40" y="50" width="10" height="10"`},
+		{name: "far corner top right", want: `<rect x="60" y="40" width="10" height="10"`},
+		{name: "2nd dimension 0 tick low", want: `<text x="34" y="55" text-anchor="end" dominant-baseline="middle">0</text>`},
+		{name: "2nd dimension 1 tick high", want: `<text x="34" y="45" text-anchor="end" dominant-baseline="middle">1</text>`},
+		{name: "1st dimension ticks below", want: `<text x="45" y="73" text-anchor="middle">0</text>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Orthotope.WriteSVG() lacks %s in\n%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package orth
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Terminal draws an Orthotope to a terminal again and again, each drawing
+// over the last. On a TTY each cluster gets its own color and the 2nd plane
+// dimension runs bottom to top, as in WriteSVG, with the SpanningPath, if any,
+// marked (); elsewhere drawings are appended as plain text, as String draws
+// them.
+type Terminal struct {
+	w     io.Writer
+	tty   bool
+	slice []SliceOption
+	lines int
+}
+
+// NewTerminal returns a Terminal writing to w, drawing the cross-section
+// chosen by opts, the first one if several are.
+func NewTerminal(w io.Writer, opts ...SliceOption) *Terminal {
+	return &Terminal{w: w, tty: isTerminal(w), slice: opts}
+}
+
+// Interactive reports whether the Terminal draws in place on a TTY.
+func (t *Terminal) Interactive() bool {
+	return t.tty
+}
+
+// Draw draws o with a status line giving step, the fraction of o occupied, the
+// size of its largest cluster and whether it is complete.
+func (t *Terminal) Draw(o *Orthotope, step int) error {
+
+	complete, err := o.BridgeComplete()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	var labels *Labeling
+	if t.tty && len(o.Lengths) >= 2 {
+		v, err := o.newView(t.slice)
+		if err != nil {
+			return err
+		}
+		t.grid(&b, v)
+		labels = v.labels
+	} else {
+		b.WriteString(o.String())
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+		labels = o.Clusters()
+	}
+
+	largest := 0
+	for _, c := range labels.Clusters {
+		if c.Size > largest {
+			largest = c.Size
+		}
+	}
+	done := "no"
+	if complete {
+		done = "yes"
+	}
+	fmt.Fprintf(&b, "step %d  occupied %.1f%%  largest cluster %d  complete %s\n",
+		step, 100*float64(o.nBuilt)/float64(o.size), largest, done)
+
+	frame := b.String()
+	if t.tty {
+		// Move to the start of the last drawing and clear it.
+		if t.lines > 0 {
+			fmt.Fprintf(t.w, "\x1b[%dF", t.lines)
+		}
+		frame = "\x1b[J" + frame
+	} else if t.lines > 0 {
+		frame = "\n" + frame
+	}
+	t.lines = strings.Count(b.String(), "\n")
+
+	_, err = io.WriteString(t.w, frame)
+	return err
+}
+
+// grid draws v in color, two characters to a cell.
+func (t *Terminal) grid(b *strings.Builder, v *view) {
+
+	if len(v.o.Lengths) > 2 {
+		b.WriteString(v.label() + "\n")
+	}
+	for cy := 0; cy < v.height(); cy++ {
+		for cx := 0; cx < v.width(); cx++ {
+			label, spanning, onPath := v.cell(cx, cy)
+			if label == 0 {
+				b.WriteString("\x1b[0m· ")
+				continue
+			}
+			c := clusterColor(label, spanning)
+			fmt.Fprintf(b, "\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+			if onPath {
+				b.WriteString("\x1b[30m()")
+			} else {
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+}
+
+// isTerminal reports whether w is a character device such as a TTY.
+func isTerminal(w io.Writer) bool {
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,122 @@
+package orth
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestTerminal_Draw(t *testing.T) {
+
+	type fields struct {
+		Lengths []int
+		built   [][]int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		tty    bool
+		draws  int
+		want   string
+		// wantIn are substrings of a TTY drawing, whose colors are not spelled
+		// out.
+		wantIn []string
+	}{
+		{
+			name: "plain",
+			fields: fields{
+				Lengths: []int{3, 2},
+				built:   [][]int{{0, 0}, {1, 0}, {2, 1}},
+			},
+			draws: 2,
+			want: " B B .\n . . B\nstep 1  occupied 50.0%  largest cluster 2  complete no\n" +
+				"\n B B .\n . . B\nstep 2  occupied 50.0%  largest cluster 2  complete no\n",
+		},
+		{
+			name: "plain 1D",
+			fields: fields{
+				Lengths: []int{3},
+				built:   [][]int{{0}, {1}, {2}},
+			},
+			draws: 1,
+			want:  " B B B\nstep 1  occupied 100.0%  largest cluster 3  complete yes\n",
+		},
+		{
+			name: "tty redraws in place",
+			fields: fields{
+				Lengths: []int{3, 2},
+				built:   [][]int{{0, 0}, {1, 0}, {2, 0}},
+			},
+			tty:   true,
+			draws: 2,
+			wantIn: []string{
+				"\x1b[J",
+				"\x1b[3F",
+				"()",
+				"\x1b[0m· ",
+				"step 2  occupied 50.0%  largest cluster 3  complete yes\n",
+			},
+		},
+		{
+			name: "tty slice label",
+			fields: fields{
+				Lengths: []int{2, 2, 2},
+				built:   [][]int{{0, 0, 0}},
+			},
+			tty:    true,
+			draws:  1,
+			wantIn: []string{"[*, *, 0]\n", "step 1  occupied 12.5%  largest cluster 1  complete no\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrthotope(t, tt.fields.Lengths, nil, tt.fields.built...)
+			var buf bytes.Buffer
+			term := NewTerminal(&buf)
+			term.tty = tt.tty
+			for step := 1; step <= tt.draws; step++ {
+				if err := term.Draw(o, step); err != nil {
+					t.Fatalf("Terminal.Draw() error = %v", err)
+				}
+			}
+			got := buf.String()
+			if !tt.tty {
+				if got != tt.want {
+					t.Errorf("Terminal.Draw() = %q, want %q", got, tt.want)
+				}
+				return
+			}
+			for _, s := range tt.wantIn {
+				if !strings.Contains(got, s) {
+					t.Errorf("Terminal.Draw() = %q, want it to contain %q", got, s)
+				}
+			}
+		})
+	}
+}
+
+func TestNewTerminal_notTTY(t *testing.T) {
+
+	f, err := ioutil.TempFile(t.TempDir(), "term")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tests := []struct {
+		name string
+		w    io.Writer
+	}{
+		{name: "buffer", w: &bytes.Buffer{}},
+		{name: "file", w: f},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if NewTerminal(tt.w).Interactive() {
+				t.Errorf("NewTerminal(%T).Interactive() = true, want false", tt.w)
+			}
+		})
+	}
+}